@@ -0,0 +1,33 @@
+package game
+
+import (
+	"github.com/jhshelnu/wordcraft/words"
+	"time"
+	"unicode/utf8"
+)
+
+const announcementHistorySize = 20 // how many announcements a lobby remembers, for clients who join later
+const maxAnnouncementLength = 200  // in characters
+
+// onAnnouncement lets the host broadcast some text to everyone in the lobby, e.g. the rules for a custom game
+// any profanity in the text is masked (see words.Censor), since everyone in the lobby sees it, whether they asked to or not
+func (lobby *Lobby) onAnnouncement(message Message) {
+	if !lobby.isHost(message.From) {
+		lobby.denyPermission(message)
+		return
+	}
+
+	text, err := DecodeContent[string](message)
+	if err != nil || text == "" || utf8.RuneCountInString(text) > maxAnnouncementLength {
+		return
+	}
+
+	announcement := AnnouncementContent{
+		Text:     words.Censor(text),
+		FromName: lobby.clients[message.From].displayName,
+		SentAt:   time.Now().UnixMilli(),
+	}
+	lobby.announcements.add(announcement)
+	lobby.logger.Info("host made an announcement", "client", lobby.clients[message.From].String())
+	lobby.BroadcastMessage(Message{Type: Announcement, Content: announcement})
+}
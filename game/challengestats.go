@@ -0,0 +1,74 @@
+package game
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+const hardestChallengesCount = 3 // how many challenges GameOverContent.HardestChallenges lists
+
+// ChallengeStats counts how the answers submitted for a challenge fared
+type ChallengeStats struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+// rejectionRate returns the fraction of answers for the challenge which were rejected
+func (stats ChallengeStats) rejectionRate() float64 {
+	return float64(stats.Rejected) / float64(stats.Accepted+stats.Rejected)
+}
+
+// ChallengeStats returns how the answers to each challenge of the current (or last) game fared, keyed by challenge
+// the stats are copied while holding challengeStatsMutex, so this is safe to call from any goroutine
+func (lobby *Lobby) ChallengeStats() map[string]ChallengeStats {
+	lobby.challengeStatsMutex.Lock()
+	defer lobby.challengeStatsMutex.Unlock()
+
+	return maps.Clone(lobby.challengeStats)
+}
+
+// recordAnswerOutcome counts an answer to the current challenge as accepted or rejected
+func (lobby *Lobby) recordAnswerOutcome(accepted bool) {
+	lobby.challengeStatsMutex.Lock()
+	defer lobby.challengeStatsMutex.Unlock()
+
+	stats := lobby.challengeStats[lobby.currentChallenge]
+	if accepted {
+		stats.Accepted++
+	} else {
+		stats.Rejected++
+	}
+	lobby.challengeStats[lobby.currentChallenge] = stats
+}
+
+func (lobby *Lobby) resetChallengeStats() {
+	lobby.challengeStatsMutex.Lock()
+	defer lobby.challengeStatsMutex.Unlock()
+
+	lobby.challengeStats = make(map[string]ChallengeStats)
+}
+
+// hardestChallenges returns up to hardestChallengesCount challenges from this game with the highest rejection rate, hardest first
+// challenges which had no answers rejected aren't included. ties go to the challenge with more rejections
+func (lobby *Lobby) hardestChallenges() []string {
+	lobby.challengeStatsMutex.Lock()
+	defer lobby.challengeStatsMutex.Unlock()
+
+	var challenges []string
+	for challenge, stats := range lobby.challengeStats {
+		if stats.Rejected > 0 {
+			challenges = append(challenges, challenge)
+		}
+	}
+
+	slices.SortFunc(challenges, func(c1, c2 string) int {
+		s1, s2 := lobby.challengeStats[c1], lobby.challengeStats[c2]
+		return cmp.Or(
+			cmp.Compare(s2.rejectionRate(), s1.rejectionRate()),
+			cmp.Compare(s2.Rejected, s1.Rejected),
+			cmp.Compare(c1, c2),
+		)
+	})
+	return challenges[:min(len(challenges), hardestChallengesCount)]
+}
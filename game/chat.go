@@ -0,0 +1,60 @@
+package game
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+const chatHistorySize = 100      // how many chat messages a lobby remembers
+const chatHistorySent = 20       // how many of the most recent chat messages are sent to clients who join later
+const maxChatMessageLength = 200 // in characters
+
+const (
+	chatBurst  = 3               // how many chat messages a client can send at once
+	chatWindow = 5 * time.Second // how long it takes for a client to be able to send chatBurst messages again
+)
+
+// rateLimiter is a token bucket, allowing up to chatBurst messages at once, with tokens refilling gradually over chatWindow
+type rateLimiter struct {
+	tokens     float64   // how many messages can be sent right now (fractional while refilling)
+	lastRefill time.Time // when tokens was last brought up to date
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{tokens: chatBurst, lastRefill: time.Now()}
+}
+
+// allow returns whether a message can be sent now, using up a token if so
+func (limiter *rateLimiter) allow() bool {
+	now := time.Now()
+	refilled := now.Sub(limiter.lastRefill).Seconds() * chatBurst / chatWindow.Seconds()
+	limiter.tokens = min(limiter.tokens+refilled, chatBurst)
+	limiter.lastRefill = now
+
+	if limiter.tokens < 1 {
+		return false
+	}
+	limiter.tokens--
+	return true
+}
+
+func (lobby *Lobby) onChatMessage(message Message) {
+	text, err := DecodeContent[string](message)
+	if err != nil || text == "" || utf8.RuneCountInString(text) > maxChatMessageLength {
+		return
+	}
+
+	limiter, ok := lobby.chatTokens[message.From]
+	if !ok {
+		limiter = newRateLimiter()
+		lobby.chatTokens[message.From] = limiter
+	}
+	if !limiter.allow() {
+		lobby.telemetry.droppedMessages++
+		return
+	}
+
+	chat := ChatMessageContent{From: message.From, Text: text, SentAt: time.Now().UnixMilli()}
+	lobby.chatHistory.add(chat)
+	lobby.BroadcastMessage(Message{Type: ChatMessage, Content: chat})
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestChatMessage(t *testing.T) {
+	lobby := newTestLobby(DefaultLobbySettings())
+	defer close(lobby.done)
+	sender := joinTestClient(lobby)
+	drainMessages(sender)
+
+	for _, text := range []string{"", "one", "two", "three", "four"} {
+		lobby.onMessage(Message{From: sender.id, Type: ChatMessage, Content: text})
+	}
+
+	// the empty message is dropped, as is the fourth, since only chatBurst can be sent at once
+	var received []ChatMessageContent
+	for _, message := range drainMessages(sender) {
+		if message.Type == ChatMessage {
+			received = append(received, message.Content.(ChatMessageContent))
+		}
+	}
+	if len(received) != chatBurst {
+		t.Fatalf("broadcast %+v, want the first %d non-empty messages", received, chatBurst)
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if received[i].From != sender.id || received[i].Text != want {
+			t.Errorf("message %d is %+v, want %q from client %d", i, received[i], want, sender.id)
+		}
+	}
+}
@@ -3,54 +3,207 @@ package game
 import (
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DebugMessages enables logging of every message sent or received over a client's websocket.
+// This leaks game state (e.g. answers) into the logs, so it must never be enabled in production
+var DebugMessages = false
+
+// ClientDisconnected, if set, is called once for every client whose connection closes, e.g. to keep count of active connections
+var ClientDisconnected func()
+
+const debugContentLength = 50 // how many characters of a message's content to include in debug logs
+
+const (
+	messageBurst       = 10  // how many messages a client can send at once
+	messagesPerSecond  = 5   // how quickly a client's rate limiter refills, once the burst is used up
+	maxDroppedMessages = 100 // how many messages a client can have dropped by its rate limiter before it is kicked
 )
 
 type Client struct {
-	id           int             // uniquely identifies the Client within the lobby
-	displayName  string          // the display name for the client (shown to other players)
-	iconName     string          // the file name of the icon to show for this client in the lobby
-	lobby        *Lobby          // holds a reference to the lobby that the client is in
-	ws           *websocket.Conn // holds a reference to the WebSocket connection
-	write        chan Message    // a write channel used by the lobby to pass messages that the client should transmit over the websocket
-	disconnected chan bool       // a channel used by the client's Read and Write goroutines to synchronize disconnects
+	id             int             // uniquely identifies the Client within the lobby
+	displayName    string          // the display name for the client (shown to other players). only changed by the lobby, with setDisplayName
+	logName        atomic.Value    // the name String logs the client under, see setDisplayName
+	iconName       string          // the file name of the icon to show for this client in the lobby
+	lobby          *Lobby          // holds a reference to the lobby that the client is in
+	ws             *websocket.Conn // holds a reference to the WebSocket connection
+	write          chan Message    // a write channel used by the lobby to pass messages that the client should transmit over the websocket
+	disconnected   chan bool       // closed once the client disconnects, so that both the Read and Write goroutines stop
+	closeOnce      sync.Once       // ensures the disconnect is only handled once, no matter which goroutine notices it first
+	format         atomic.Int32    // the messageFormat used for messages sent to this client. set by the lobby, read by the Write goroutine
+	score          int             // points earned this game, only changed by the lobby
+	usedDoubleDown bool            // whether the client has doubled down this game, only changed by the lobby
+	seatOf         *Client         // in hot seat lobbies, the device this seat is played on. seats have no connection (or goroutines) of their own
+	streak         int             // how many answers in a row the client has had accepted this game, only changed by the lobby
+	closedCh       chan struct{}   // closed by the Read goroutine once it has stopped, i.e. the connection has closed
+	rejoinToken    string          // secret which lets the client take back its place if it reconnects in time, see Lobby.holdForReconnect
+	spectator      bool            // whether the client joined while a game was in progress, and is only watching. they play again once they opt in, see Lobby.onPromoteObserver
+	eliminatedAt   *time.Time      // when the client went out of the current game, or nil if they haven't (or aren't playing)
+	options        ClientOptions   // how the connection is kept alive, see JoinClientToLobby
+	limiter        *rate.Limiter   // limits how quickly the client can send messages, so it can't flood the lobby. only used by the Read goroutine
+	droppedCount   int             // how many of the client's messages the limiter has dropped this connection, only changed by the Read goroutine
+	rttMs          atomic.Int64    // the round-trip time of the client's last answered ping, in milliseconds. set by the Read goroutine, see recordPong
+	teamId         int             // in teams lobbies, which team the client is on (1 or 2), see Lobby.assignTeam. 0 otherwise
 }
 
-func JoinClientToLobby(ws *websocket.Conn, lobby *Lobby) error {
-	if ws == nil {
-		return errors.New("websocket connection must already be established")
-	}
+// ClientOptions configures the heartbeat which detects connections that went away without closing, e.g. when a phone goes to sleep
+type ClientOptions struct {
+	PingInterval time.Duration // how often the client is pinged. 0 disables pings
+	PongTimeout  time.Duration // how long the client can go without answering a ping (or sending anything) before it is disconnected. 0 waits forever
+}
 
-	if lobby == nil {
-		return errors.New("client must belong to a lobby")
+// DefaultClientOptions returns the options used for clients unless the server is configured otherwise
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		PingInterval: 20 * time.Second,
+		PongTimeout:  30 * time.Second,
 	}
+}
+
+type joinErrorCode string
+
+const (
+	LobbyEnded  joinErrorCode = "lobby_ended"  // the lobby ended before the client could join it
+	Kicked      joinErrorCode = "kicked"       // the client was kicked from the lobby by the host
+	NotFinalist joinErrorCode = "not_finalist" // the lobby is a tournament final, and the client didn't win a qualifying lobby
+)
+
+// JoinError is returned by JoinClientToLobby when the lobby could not accept the client
+type JoinError struct {
+	Code joinErrorCode
+}
 
-	Id := lobby.GetNextClientId()
+func (e JoinError) Error() string {
+	return fmt.Sprintf("failed to join lobby: %s", e.Code)
+}
+
+// NewClient creates a client with the default name and icon for its id, sending messages as JSON.
+// The client's Read and Write goroutines are not started, see JoinClientToLobby
+func NewClient(id int, ws *websocket.Conn, lobby *Lobby) *Client {
 	client := &Client{
-		id:           Id,
-		displayName:  fmt.Sprintf("Player %d", Id),
-		iconName:     lobby.GetDefaultIconName(Id),
+		id:           id,
+		iconName:     lobby.GetDefaultIconName(id),
 		lobby:        lobby,
 		ws:           ws,
 		write:        make(chan Message),
 		disconnected: make(chan bool),
+		closedCh:     make(chan struct{}),
+		rejoinToken:  uuid.NewString(),
+		limiter:      rate.NewLimiter(messagesPerSecond, messageBurst),
+	}
+	client.setDisplayName(fmt.Sprintf("Player %d", id))
+	return client
+}
+
+// JoinClientToLobby adds a newly connected client to the lobby
+// if rejoinToken belongs to a client whose place is being held (see Lobby.holdForReconnect), the client takes that place back
+// tournament finals only let in clients whose rejoinToken was registered by the lobby they won (see Lobby.RegisterFinalist)
+func JoinClientToLobby(ws *websocket.Conn, lobby *Lobby, rejoinToken string, options ClientOptions) error {
+	if ws == nil {
+		return errors.New("websocket connection must already be established")
+	}
+
+	if lobby == nil {
+		return errors.New("client must belong to a lobby")
 	}
 
+	if lobby.IsKicked(rejoinToken) {
+		closeFrame := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, string(Kicked))
+		_ = ws.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(time.Second))
+		_ = ws.Close()
+		return JoinError{Code: Kicked}
+	}
+
+	if lobby.settings.TournamentFinal && !lobby.IsFinalist(rejoinToken) {
+		closeFrame := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, string(NotFinalist))
+		_ = ws.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(time.Second))
+		_ = ws.Close()
+		return JoinError{Code: NotFinalist}
+	}
+
+	var client *Client
+	if id, ok := lobby.ClaimRejoinToken(rejoinToken); ok {
+		client = NewClient(id, ws, lobby)
+		client.rejoinToken = rejoinToken
+	} else if lobby.settings.TournamentFinal {
+		// finalists keep the token they won with, so they are still let in if they refresh the page
+		client = NewClient(lobby.GetNextClientId(), ws, lobby)
+		client.rejoinToken = rejoinToken
+	} else {
+		client = NewClient(lobby.GetNextClientId(), ws, lobby)
+	}
+	client.options = options
+
 	go client.Write()
-	go client.Read()
-	lobby.join <- client
 
-	return nil
+	// the lobby may end at any moment (e.g. everyone else leaves), in which case nobody is listening on the join channel
+	select {
+	case lobby.join <- client:
+		go client.Read()
+		return nil
+	case <-lobby.Done():
+		client.close()
+		return JoinError{Code: LobbyEnded}
+	}
 }
 
 func (c *Client) Write() {
 	defer c.close()
 
+	// when batching is enabled, messages are held for batchingDelay after the first one arrives, then all sent in one frame
+	batchingDelay := time.Duration(c.lobby.settings.BatchingDelayMs) * time.Millisecond
+	var batch []Message
+	var flush <-chan time.Time // fires once the batch has been held for batchingDelay, or nil when there is no batch
+
+	var ping <-chan time.Time // nil when pings are disabled
+	if c.options.PingInterval > 0 {
+		pingTicker := time.NewTicker(c.options.PingInterval)
+		defer pingTicker.Stop()
+		ping = pingTicker.C
+	}
+
 	for {
 		select {
 		case message := <-c.write:
-			err := c.ws.WriteJSON(message)
-			if err != nil {
+			if DebugMessages {
+				c.lobby.logger.Info("sent message", "clientId", c.id, "type", message.Type, "content", c.lobby.debugContent(message.Content))
+			}
+			batch = append(batch, message)
+
+			closeCode, closeReason, closing := c.getCloseFrame(message)
+			if batchingDelay > 0 && !closing {
+				if flush == nil {
+					flush = time.After(batchingDelay)
+				}
+				continue
+			}
+
+			if !c.writeBatch(batch) {
+				return
+			}
+			batch, flush = nil, nil
+
+			// the lobby is closing (or the client was kicked), so close the connection properly rather than leaving the client to notice it's gone
+			if closing {
+				c.closeHandshake(closeCode, closeReason)
+				return
+			}
+		case <-flush:
+			if !c.writeBatch(batch) {
+				return
+			}
+			batch, flush = nil, nil
+		case <-ping:
+			// the pong is handled by the Read goroutine, see refreshReadDeadline and recordPong
+			sentAt := []byte(strconv.FormatInt(time.Now().UnixMilli(), 10))
+			if err := c.ws.WriteControl(websocket.PingMessage, sentAt, time.Now().Add(c.options.PingInterval)); err != nil {
 				return
 			}
 		case <-c.disconnected:
@@ -59,8 +212,47 @@ func (c *Client) Write() {
 	}
 }
 
+// writeBatch sends the messages to the client in a single frame. a lone message is sent as is, rather than as an array of one
+// returns false if the connection has failed
+func (c *Client) writeBatch(batch []Message) bool {
+	var payload any = batch
+	if len(batch) == 1 {
+		payload = batch[0]
+	}
+
+	frameType, data, err := encodeMessage(payload, messageFormat(c.format.Load()))
+	if err != nil {
+		c.lobby.logger.Error("failed to encode messages", "client", c.String(), "count", len(batch), "error", err)
+		return true
+	}
+	return c.ws.WriteMessage(frameType, data) == nil
+}
+
+// getCloseFrame returns the close frame to send once the message has been sent, if the message means the connection is over
+func (c *Client) getCloseFrame(message Message) (int, string, bool) {
+	if message.Type == Shutdown {
+		return websocket.CloseGoingAway, "lobby closed", true
+	}
+	if message.Type == ClientKicked && MustDecodeContent[ClientKickedContent](message).ClientId == c.id {
+		return websocket.ClosePolicyViolation, string(Kicked), true
+	}
+	if message.Type == LobbyFull {
+		return websocket.CloseTryAgainLater, "lobby full", true
+	}
+	return 0, "", false
+}
+
 func (c *Client) Read() {
 	defer c.close()
+	defer close(c.closedCh)
+
+	// a client which stops answering pings is gone, so reading fails once the deadline passes and the client is disconnected
+	c.refreshReadDeadline()
+	c.ws.SetPongHandler(func(payload string) error {
+		c.refreshReadDeadline()
+		c.recordPong(payload)
+		return nil
+	})
 
 	for {
 		// check if we've disconnected without blocking
@@ -71,23 +263,149 @@ func (c *Client) Read() {
 			// nothing to do
 		}
 
-		var message Message
-		err := c.ws.ReadJSON(&message)
+		frameType, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.refreshReadDeadline()
+
+		if retryAfter, allowed := c.allowMessage(); !allowed {
+			if !c.dropMessage(retryAfter) {
+				return
+			}
+			continue
+		}
+
+		raw, err := decodeRaw(frameType, data)
+		if err != nil {
+			return
+		}
+
+		var validationErr ValidationError
+		if errors.As(validateMessage(raw), &validationErr) {
+			if DebugMessages {
+				c.lobby.logger.Info("received invalid message", "clientId", c.id, "error", validationErr)
+			}
+			select {
+			case c.write <- Message{Type: MessageRejected, Content: validationErr}:
+				continue
+			case <-c.disconnected:
+				return
+			}
+		}
+
+		message, err := decodeMessage(frameType, data)
 		if err != nil {
 			return
 		}
 
 		message.From = c.id
-		c.lobby.read <- message
+		if DebugMessages {
+			c.lobby.logger.Info("received message", "clientId", c.id, "type", message.Type, "content", c.lobby.debugContent(message.Content))
+		}
+		select {
+		case c.lobby.read <- message:
+		case <-c.lobby.Done():
+			return
+		}
+	}
+}
+
+// allowMessage returns whether the client is allowed to send a message now, using up a token from its limiter if so
+// if not, it returns how long the client needs to wait before it can send one
+func (c *Client) allowMessage() (time.Duration, bool) {
+	reservation := c.limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel() // the message is dropped rather than delayed, so it shouldn't use up a token
+		return delay, false
+	}
+	return 0, true
+}
+
+// dropMessage lets the client know their message was dropped for exceeding the rate limit, and reports them to the lobby once they've had maxDroppedMessages dropped
+// returns false if the client has disconnected
+func (c *Client) dropMessage(retryAfter time.Duration) bool {
+	c.droppedCount++
+	if c.droppedCount == maxDroppedMessages {
+		// the lobby kicks the client, after which their connection is closed by the Write goroutine
+		select {
+		case c.lobby.rateLimitExceeded <- c:
+		case <-c.lobby.Done():
+			return false
+		}
+	}
+
+	select {
+	case c.write <- Message{Type: RateLimited, Content: RateLimitedContent{RetryAfterMs: retryAfter.Milliseconds()}}:
+		return true
+	case <-c.disconnected:
+		return false
+	}
+}
+
+// refreshReadDeadline gives the client another PongTimeout to be heard from, since it has just shown it is still there
+func (c *Client) refreshReadDeadline() {
+	if c.options.PongTimeout > 0 {
+		_ = c.ws.SetReadDeadline(time.Now().Add(c.options.PongTimeout))
+	}
+}
+
+// closeHandshake sends the client a close frame with the given code and reason, and waits (up to the lobby's WebSocketCloseTimeoutMs) for the client to echo it
+// the echo is read (and the connection's closure noticed) by the Read goroutine, which closes closedCh once it stops
+func (c *Client) closeHandshake(code int, reason string) {
+	timeout := time.Duration(c.lobby.settings.WebSocketCloseTimeoutMs) * time.Millisecond
+	closeFrame := websocket.FormatCloseMessage(code, reason)
+	if err := c.ws.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(timeout)); err != nil {
+		return
+	}
+
+	select {
+	case <-c.closedCh:
+	case <-time.After(timeout):
+		c.lobby.logger.Warn("close frame not acknowledged", "client", c.String(), "timeoutMs", timeout.Milliseconds())
 	}
 }
 
 func (c *Client) close() {
-	c.disconnected <- true // tell the other client goroutine to disconnect
-	c.lobby.leave <- c
-	_ = c.ws.Close()
+	c.closeOnce.Do(func() {
+		close(c.disconnected) // tell the other client goroutine to disconnect
+		select {
+		case c.lobby.leave <- c:
+		case <-c.lobby.Done():
+			// the lobby has already ended, so there's nobody to tell
+		}
+		_ = c.ws.Close()
+		if ClientDisconnected != nil {
+			ClientDisconnected()
+		}
+	})
+}
+
+// Id returns the id the client was given in its lobby
+func (c *Client) Id() int {
+	return c.id
+}
+
+// setDisplayName changes the name the client is shown to other players with
+// the Read and Write goroutines log the client too, so they get a copy of the name (see String) rather than reading displayName
+func (c *Client) setDisplayName(displayName string) {
+	c.displayName = displayName
+	c.logName.Store(c.lobby.safeDisplayName(c))
 }
 
 func (c *Client) String() string {
-	return fmt.Sprintf("Client[id=%d, displayName='%s']", c.id, c.displayName)
+	return fmt.Sprintf("Client[id=%d, displayName='%s']", c.id, c.logName.Load())
+}
+
+// truncateContent formats a message's content for debug logging, cutting it off at debugContentLength characters
+func truncateContent(content any) string {
+	if content == nil {
+		return ""
+	}
+
+	formatted := []rune(fmt.Sprintf("%+v", content))
+	if len(formatted) > debugContentLength {
+		return string(formatted[:debugContentLength]) + "..."
+	}
+	return string(formatted)
 }
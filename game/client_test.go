@@ -0,0 +1,493 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestLobby creates a lobby which hasn't been started, so tests can play the part of its goroutine
+func newTestLobby(settings LobbySettings) *Lobby {
+	return NewLobby(make(chan uuid.UUID, 1), settings, "", nil, nil)
+}
+
+// newTestConn opens a websocket connection to an httptest.Server
+// it returns the server's end of the connection, as a Client would have, and the peer's end, playing the part of the browser
+func newTestConn(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	serverConns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade the connection: %v", err)
+			return
+		}
+		serverConns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = peer.Close() })
+
+	return <-serverConns, peer
+}
+
+// newConnectedClient connects a client to a lobby with the given settings, with its Read and Write goroutines running
+// the lobby isn't started, so the test plays the part of its goroutine. it ends along with the test
+func newConnectedClient(t *testing.T, settings LobbySettings) (*Client, *websocket.Conn) {
+	t.Helper()
+
+	lobby := newTestLobby(settings)
+	conn, peer := newTestConn(t)
+	client := NewClient(1, conn, lobby)
+	go client.Write()
+	go client.Read()
+	t.Cleanup(func() {
+		close(lobby.done)
+		client.close()
+	})
+	return client, peer
+}
+
+// readFrames reads frames from peer until it has been sent count messages, returning how many frames they came in
+func readFrames(t *testing.T, peer *websocket.Conn, count int) int {
+	t.Helper()
+
+	_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frames := 0
+	for received := 0; received < count; frames++ {
+		_, data, err := peer.ReadMessage()
+		if err != nil {
+			t.Fatalf("failed to read a frame after %d of %d messages: %v", received, count, err)
+		}
+
+		var batch []json.RawMessage
+		if json.Unmarshal(data, &batch) == nil {
+			received += len(batch)
+		} else {
+			received++
+		}
+	}
+	return frames
+}
+
+// pipeListener is a net.Listener which accepts the server's end of a single net.Pipe
+type pipeListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (listener *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-listener.conns:
+		return conn, nil
+	case <-listener.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (listener *pipeListener) Close() error {
+	listener.closeOnce.Do(func() { close(listener.closed) })
+	return nil
+}
+
+func (listener *pipeListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: "pipe", Net: "pipe"}
+}
+
+// newPipeConn is like newTestConn, but the websocket runs over an in-memory net.Pipe rather than a TCP socket
+func newPipeConn(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	t.Helper()
+
+	serverEnd, peerEnd := net.Pipe()
+	listener := &pipeListener{conns: make(chan net.Conn, 1), closed: make(chan struct{})}
+	listener.conns <- serverEnd
+
+	upgrader := websocket.Upgrader{}
+	serverConns := make(chan *websocket.Conn, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade the connection: %v", err)
+			return
+		}
+		serverConns <- conn
+	})}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	dialer := websocket.Dialer{NetDial: func(string, string) (net.Conn, error) { return peerEnd, nil }}
+	peer, _, err := dialer.Dial("ws://pipe/", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = peer.Close() })
+
+	return <-serverConns, peer
+}
+
+// expectClosed fails the test unless the peer's connection is closed by the server within a couple of seconds
+func expectClosed(t *testing.T, peer *websocket.Conn) {
+	t.Helper()
+
+	_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := peer.ReadMessage()
+		var netErr net.Error
+		switch {
+		case err == nil:
+			continue // messages sent before the connection closed
+		case errors.As(err, &netErr) && netErr.Timeout():
+			t.Fatal("the connection was left open")
+		}
+		return
+	}
+}
+
+func TestJoinClientToLobbyWhenLobbyEnds(t *testing.T) {
+	tests := []struct {
+		name     string
+		endLobby func(lobby *Lobby)
+	}{
+		{
+			name:     "ended before joining",
+			endLobby: func(lobby *Lobby) { close(lobby.done) },
+		},
+		{
+			// nobody reads from the join channel, so the join is still waiting when the lobby ends
+			name: "ends while joining",
+			endLobby: func(lobby *Lobby) {
+				time.AfterFunc(50*time.Millisecond, func() { close(lobby.done) })
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lobby := newTestLobby(DefaultLobbySettings())
+			conn, peer := newTestConn(t)
+
+			joined := make(chan error, 1)
+			test.endLobby(lobby)
+			go func() { joined <- JoinClientToLobby(conn, lobby, "", ClientOptions{}) }()
+
+			select {
+			case err := <-joined:
+				var joinErr JoinError
+				if !errors.As(err, &joinErr) || joinErr.Code != LobbyEnded {
+					t.Fatalf("JoinClientToLobby returned %v, want a JoinError with code %s", err, LobbyEnded)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("JoinClientToLobby is still blocked after the lobby ended")
+			}
+			expectClosed(t, peer)
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	lobby := newTestLobby(DefaultLobbySettings())
+	conn, peer := newPipeConn(t)
+
+	client := NewClient(3, conn, lobby)
+	if client.id != 3 {
+		t.Errorf("id is %d, want 3", client.id)
+	}
+	if client.displayName != "Player 3" {
+		t.Errorf("displayName is %q, want %q", client.displayName, "Player 3")
+	}
+	if want := lobby.GetDefaultIconName(3); client.iconName != want {
+		t.Errorf("iconName is %q, want %q", client.iconName, want)
+	}
+	if client.lobby != lobby || client.ws != conn {
+		t.Error("the client doesn't belong to the lobby and connection it was created with")
+	}
+	if client.rejoinToken == "" || client.rejoinToken == NewClient(4, conn, lobby).rejoinToken {
+		t.Errorf("rejoinToken %q isn't a unique secret", client.rejoinToken)
+	}
+
+	// the client is usable as created: messages sent to it are written to its connection
+	go client.Write()
+	client.write <- Message{Type: ChatMessage, Content: ChatMessageContent{From: 3, Text: "hello"}}
+
+	_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := peer.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read the message written by the client: %v", err)
+	}
+	if !strings.Contains(string(data), `"hello"`) {
+		t.Errorf("the client wrote %s, want the chat message", data)
+	}
+
+	go client.close()
+	select {
+	case leaving := <-lobby.leave:
+		if leaving != client {
+			t.Errorf("%s left the lobby, want %s", leaving, client)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the client didn't leave the lobby when it closed")
+	}
+	expectClosed(t, peer)
+}
+
+// the Read and Write goroutines log their client while the lobby can be renaming it, which `go test -race` checks is safe
+func TestClientStringDuringNameChange(t *testing.T) {
+	lobby := newTestLobby(DefaultLobbySettings())
+	client := NewClient(1, nil, lobby)
+	client.write = make(chan Message, 100) // nothing is written to a connection, so the name changes are left here
+	lobby.clients[client.id] = client
+
+	logging := make(chan struct{})
+	go func() {
+		for range 100 {
+			_ = client.String()
+		}
+		close(logging)
+	}()
+	for i := range 100 {
+		lobby.onNameChange(Message{From: client.id, Type: NameChange, Content: fmt.Sprintf("name %d", i)})
+	}
+	<-logging
+
+	if want := "Client[id=1, displayName='name 99']"; client.String() != want {
+		t.Errorf("the client is logged as %s once renamed, want %s", client, want)
+	}
+}
+
+func TestBatchingReducesFrames(t *testing.T) {
+	const messages = 50
+
+	tests := []struct {
+		name            string
+		batchingDelayMs int
+		minFrames       int
+		maxFrames       int
+	}{
+		{name: "disabled", batchingDelayMs: 0, minFrames: messages, maxFrames: messages},
+		{name: "enabled", batchingDelayMs: 50, minFrames: 1, maxFrames: 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := DefaultLobbySettings()
+			settings.BatchingDelayMs = test.batchingDelayMs
+			client, peer := newConnectedClient(t, settings)
+
+			go func() {
+				for i := range messages {
+					client.write <- Message{Type: ChatMessage, Content: ChatMessageContent{From: 1, Text: fmt.Sprintf("message %d", i)}}
+				}
+			}()
+
+			frames := readFrames(t, peer, messages)
+			t.Logf("%d messages were sent in %d frames", messages, frames)
+			if frames < test.minFrames || frames > test.maxFrames {
+				t.Errorf("%d messages were sent in %d frames, want %d to %d", messages, frames, test.minFrames, test.maxFrames)
+			}
+		})
+	}
+}
+
+func TestClientRead(t *testing.T) {
+	msgpackNameChange, err := msgpack.Marshal(map[string]any{"Type": NameChange, "Content": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		frameType    int
+		data         []byte
+		wantRejected bool // whether the message is sent back to the client as a MessageRejected, rather than passed on to the lobby
+	}{
+		{name: "json", frameType: websocket.TextMessage, data: []byte(`{"Type":"name_change","Content":"bob"}`)},
+		{name: "msgpack", frameType: websocket.BinaryMessage, data: msgpackNameChange},
+		{name: "invalid", frameType: websocket.TextMessage, data: []byte(`{"Type":"name_change","Content":5}`), wantRejected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, peer := newConnectedClient(t, DefaultLobbySettings())
+			if err := peer.WriteMessage(test.frameType, test.data); err != nil {
+				t.Fatalf("failed to send the message: %v", err)
+			}
+
+			if test.wantRejected {
+				_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+				var rejected struct{ Type messageType }
+				if err := peer.ReadJSON(&rejected); err != nil || rejected.Type != MessageRejected {
+					t.Fatalf("the client was sent %+v (%v), want a %s message", rejected, err, MessageRejected)
+				}
+				select {
+				case message := <-client.lobby.read:
+					t.Errorf("the invalid message was passed on to the lobby as %+v", message)
+				default:
+				}
+				return
+			}
+
+			select {
+			case message := <-client.lobby.read:
+				want := Message{From: client.id, Type: NameChange, Content: "bob"}
+				if message != want {
+					t.Errorf("the lobby was sent %+v, want %+v", message, want)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("the message wasn't passed on to the lobby")
+			}
+		})
+	}
+}
+
+func TestClientWriteBatch(t *testing.T) {
+	settings := DefaultLobbySettings()
+	settings.BatchingDelayMs = 50
+	client, peer := newConnectedClient(t, settings)
+	_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	// messages sent within the delay of each other go out together, in the order they were sent
+	sent := []string{"first", "second", "third"}
+	for _, text := range sent {
+		client.write <- Message{Type: ChatMessage, Content: ChatMessageContent{From: 1, Text: text}}
+	}
+	var batch []struct {
+		Type    messageType
+		Content ChatMessageContent
+	}
+	if err := peer.ReadJSON(&batch); err != nil {
+		t.Fatalf("failed to read the batch: %v", err)
+	}
+	if len(batch) != len(sent) {
+		t.Fatalf("the batch has %d messages, want %d", len(batch), len(sent))
+	}
+	for i, message := range batch {
+		if message.Type != ChatMessage || message.Content.Text != sent[i] {
+			t.Errorf("message %d of the batch is %+v, want the chat message %q", i, message, sent[i])
+		}
+	}
+
+	// a message on its own isn't wrapped in an array
+	client.write <- Message{Type: ChatMessage, Content: ChatMessageContent{From: 1, Text: "alone"}}
+	var single struct {
+		Type    messageType
+		Content ChatMessageContent
+	}
+	if err := peer.ReadJSON(&single); err != nil {
+		t.Fatalf("failed to read the lone message: %v", err)
+	}
+	if single.Type != ChatMessage || single.Content.Text != "alone" {
+		t.Errorf("the lone message is %+v, want the chat message %q", single, "alone")
+	}
+}
+
+func TestClientCloseHandshake(t *testing.T) {
+	const closeTimeoutMs = 300
+
+	tests := []struct {
+		name        string
+		acknowledge bool // whether the peer reads (and so echoes) the close frame
+		minWait     time.Duration
+		maxWait     time.Duration
+	}{
+		{name: "acknowledged", acknowledge: true, minWait: 0, maxWait: closeTimeoutMs * time.Millisecond / 2},
+		{name: "not acknowledged", acknowledge: false, minWait: closeTimeoutMs * time.Millisecond, maxWait: 2 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := DefaultLobbySettings()
+			settings.WebSocketCloseTimeoutMs = closeTimeoutMs
+			client, peer := newConnectedClient(t, settings)
+
+			sentAt := time.Now()
+			client.write <- Message{Type: Shutdown, Content: ShutdownContent{Reason: IdleTimeoutShutdown}}
+
+			closeErr := make(chan error, 1)
+			if test.acknowledge {
+				go func() {
+					_ = peer.SetReadDeadline(time.Now().Add(2 * time.Second))
+					for {
+						if _, _, err := peer.ReadMessage(); err != nil {
+							closeErr <- err
+							return
+						}
+					}
+				}()
+			}
+
+			// the client leaves the lobby once the handshake is over, one way or the other
+			select {
+			case <-client.lobby.leave:
+			case <-time.After(2 * time.Second):
+				t.Fatal("the client didn't leave the lobby after the close frame was sent")
+			}
+			if waited := time.Since(sentAt); waited < test.minWait || waited > test.maxWait {
+				t.Errorf("the client waited %s for the close frame to be acknowledged, want %s to %s", waited, test.minWait, test.maxWait)
+			}
+
+			if test.acknowledge {
+				err := <-closeErr
+				var wsCloseErr *websocket.CloseError
+				if !errors.As(err, &wsCloseErr) || wsCloseErr.Code != websocket.CloseGoingAway || wsCloseErr.Text != "lobby closed" {
+					t.Errorf("the connection closed with %v, want a %d close frame", err, websocket.CloseGoingAway)
+				}
+			}
+		})
+	}
+}
+
+func TestClientCloseOnce(t *testing.T) {
+	disconnects := 0
+	var disconnectsMutex sync.Mutex
+	ClientDisconnected = func() {
+		disconnectsMutex.Lock()
+		defer disconnectsMutex.Unlock()
+		disconnects++
+	}
+	t.Cleanup(func() { ClientDisconnected = nil })
+
+	client, peer := newConnectedClient(t, DefaultLobbySettings())
+
+	// the connection going away is noticed by the Read and Write goroutines, on top of any number of explicit closes
+	_ = peer.Close()
+	for range 5 {
+		go client.close()
+	}
+
+	select {
+	case leaving := <-client.lobby.leave:
+		if leaving != client {
+			t.Errorf("%s left the lobby, want %s", leaving, client)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the client didn't leave the lobby")
+	}
+	select {
+	case <-client.lobby.leave:
+		t.Error("the client left the lobby more than once")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	disconnectsMutex.Lock()
+	defer disconnectsMutex.Unlock()
+	if disconnects != 1 {
+		t.Errorf("ClientDisconnected was called %d times, want 1", disconnects)
+	}
+}
@@ -0,0 +1,34 @@
+package game
+
+import "time"
+
+// clockSyncInterval is how often the end of the turn is re-sent during a turn, so clients can correct for their clocks drifting from the server's
+const clockSyncInterval = 5 * time.Second
+
+// resetClockSync restarts the clock sync ticker, so the first sync of a turn comes clockSyncInterval after the turn starts
+func (lobby *Lobby) resetClockSync() {
+	lobby.stopClockSync()
+	lobby.clockSyncTicker = time.NewTicker(clockSyncInterval)
+	lobby.clockSync = lobby.clockSyncTicker.C
+}
+
+func (lobby *Lobby) stopClockSync() {
+	if lobby.clockSyncTicker != nil {
+		lobby.clockSyncTicker.Stop()
+	}
+	lobby.clockSyncTicker = nil
+	lobby.clockSync = nil
+}
+
+// onClockSync tells the clients when the current turn ends, along with the server's current time
+func (lobby *Lobby) onClockSync() {
+	if lobby.status != InProgress {
+		lobby.stopClockSync()
+		return
+	}
+
+	lobby.BroadcastMessage(Message{Type: ClockSync, Content: ClockSyncContent{
+		TurnEnd:   lobby.currentTurnEnd,
+		ServerNow: time.Now().UnixMilli(),
+	}})
+}
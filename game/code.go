@@ -0,0 +1,18 @@
+package game
+
+import "math/rand/v2"
+
+const (
+	lobbyCodeLength   = 6
+	lobbyCodeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// NewLobbyCode returns a random code, e.g. "WXYZ12", which is easier to share than a lobby's id
+// codes aren't guaranteed to be unique, so whoever keeps track of the lobbies must check for collisions
+func NewLobbyCode() string {
+	code := make([]byte, lobbyCodeLength)
+	for i := range code {
+		code[i] = lobbyCodeAlphabet[rand.IntN(len(lobbyCodeAlphabet))]
+	}
+	return string(code)
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"crypto/sha256"
+	"github.com/jhshelnu/wordcraft/words"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DailySecret is mixed into the seed of each day's challenges, so they can't be worked out ahead of time from the date alone
+var DailySecret = ""
+
+const dailyLeaderboardSize = 10 // how many of the day's best results are kept
+
+// DailyLeaderboardEntry is one client's result from a game played with LobbySettings.DailyChallenge
+type DailyLeaderboardEntry struct {
+	DisplayName string `json:"displayName"`
+	Score       int    `json:"score"`
+	Won         bool   `json:"won"`
+}
+
+var (
+	dailyMutex   sync.Mutex              // lobbies report results from their own goroutines, while the leaderboard is read by request handlers
+	dailyDay     string                  // the day dailyEntries are for, see dailyDate
+	dailyEntries []DailyLeaderboardEntry // the best results reported that day, best first
+)
+
+// dailyDate returns the day (in UTC) which t falls on, e.g. "2024-06-30"
+func dailyDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func dailySeed(day string) [32]byte {
+	return sha256.Sum256([]byte(day + DailySecret))
+}
+
+// DailyChallenge returns today's date along with its challenge, which is the first one given in every daily game played today
+func DailyChallenge() (day string, challenge string) {
+	day = dailyDate(time.Now())
+	return day, words.GetSeededChallenge(dailySeed(day), 0, words.ChallengeEasy)
+}
+
+// DailyLeaderboard returns the best results from daily games played today, best first
+func DailyLeaderboard() []DailyLeaderboardEntry {
+	dailyMutex.Lock()
+	defer dailyMutex.Unlock()
+
+	if dailyDay != dailyDate(time.Now()) {
+		return []DailyLeaderboardEntry{}
+	}
+	return slices.Clone(dailyEntries)
+}
+
+// recordDailyResults adds results from a game played on the given day to that day's leaderboard
+// results from a previous day (i.e. a game which finished after midnight) are ignored, since that day's leaderboard is gone
+func recordDailyResults(day string, results []DailyLeaderboardEntry) {
+	dailyMutex.Lock()
+	defer dailyMutex.Unlock()
+
+	if day < dailyDay {
+		return
+	}
+	if day > dailyDay {
+		dailyDay = day
+		dailyEntries = nil
+	}
+
+	// highest score first, with winners ahead of anyone they tied with
+	dailyEntries = append(dailyEntries, results...)
+	slices.SortStableFunc(dailyEntries, func(e1, e2 DailyLeaderboardEntry) int {
+		if e1.Score != e2.Score {
+			return e2.Score - e1.Score
+		}
+		switch {
+		case e1.Won && !e2.Won:
+			return -1
+		case e2.Won && !e1.Won:
+			return 1
+		default:
+			return 0
+		}
+	})
+	dailyEntries = dailyEntries[:min(len(dailyEntries), dailyLeaderboardSize)]
+}
+
+// getDailyChallenge returns the challenge for the current turn of a daily game
+// every daily game on the same day gets the same challenges in the same order. the day is the one the game started on
+func (lobby *Lobby) getDailyChallenge(difficulty words.ChallengeDifficulty) string {
+	return words.GetSeededChallenge(dailySeed(dailyDate(lobby.startedAt)), lobby.turnCount-1, difficulty)
+}
+
+// reportDailyResults adds everyone who played the game which just ended to the daily leaderboard, if this is a daily lobby
+func (lobby *Lobby) reportDailyResults(gameOver GameOverContent) {
+	if !lobby.settings.DailyChallenge {
+		return
+	}
+
+	results := make([]DailyLeaderboardEntry, 0, len(gameOver.FinalStandings))
+	for _, standing := range gameOver.FinalStandings {
+		client, ok := lobby.clients[standing.ClientId]
+		if !ok {
+			client = lobby.reconnecting[standing.ClientId].client
+		}
+		results = append(results, DailyLeaderboardEntry{
+			DisplayName: client.displayName,
+			Score:       client.score,
+			Won:         standing.ClientId == gameOver.WinnerId,
+		})
+	}
+	recordDailyResults(dailyDate(lobby.startedAt), results)
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// messageFormat is how messages are serialised over a client's websocket
+type messageFormat int32
+
+const (
+	jsonFormat    messageFormat = iota // the default. sent as text frames
+	msgpackFormat                      // MessagePack, sent as binary frames. clients opt in with a Negotiate message
+)
+
+// the formats clients can ask for in a Negotiate message
+var negotiableFormats = map[string]messageFormat{
+	"json":    jsonFormat,
+	"msgpack": msgpackFormat,
+}
+
+// encodeMessage serialises message in the given format, returning the websocket frame type to send it as
+// message is either a single Message, or a batch of them (see LobbySettings.BatchingDelayMs) which is sent as an array
+func encodeMessage(message any, format messageFormat) (int, []byte, error) {
+	switch format {
+	case msgpackFormat:
+		var buf bytes.Buffer
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetCustomStructTag("json") // so field names (and omitted fields) match the JSON format
+		if err := encoder.Encode(message); err != nil {
+			return 0, nil, err
+		}
+		return websocket.BinaryMessage, buf.Bytes(), nil
+	default:
+		data, err := json.Marshal(message)
+		return websocket.TextMessage, data, err
+	}
+}
+
+// decodeMessage parses a message sent by a client. binary frames are MessagePack, anything else is JSON
+func decodeMessage(frameType int, data []byte) (Message, error) {
+	var message Message
+	var err error
+	if frameType == websocket.BinaryMessage {
+		err = msgpack.Unmarshal(data, &message)
+	} else {
+		err = json.Unmarshal(data, &message)
+	}
+	return message, err
+}
+
+// DecodeContent returns a message's content as a T, or an error if the content is of a different type
+// ints are decoded with contentInt, since the number types depend on the format the message was sent in
+func DecodeContent[T any](message Message) (T, error) {
+	var content T
+	if _, wantsInt := any(content).(int); wantsInt {
+		n, ok := contentInt(message.Content)
+		if !ok {
+			return content, fmt.Errorf("%s content must be an int, got %T", message.Type, message.Content)
+		}
+		return any(n).(T), nil
+	}
+
+	content, ok := message.Content.(T)
+	if !ok {
+		return content, fmt.Errorf("%s content must be a %T, got %T", message.Type, content, message.Content)
+	}
+	return content, nil
+}
+
+// MustDecodeContent is like DecodeContent, but panics if the content is of a different type
+// it's only for messages whose content type is guaranteed, e.g. ones built by the server itself
+func MustDecodeContent[T any](message Message) T {
+	content, err := DecodeContent[T](message)
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+// contentInt returns a message's content as an int, however the format it was sent in represents numbers
+// JSON numbers are decoded as float64, while msgpack ones keep whichever integer type they were packed as
+func contentInt(content any) (int, bool) {
+	switch n := content.(type) {
+	case float64:
+		return int(n), n == float64(int(n))
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+// decodeRaw parses a message sent by a client into plain maps, slices and values, as encoding/json would
+// msgpack messages are converted to match, so that they can be checked against the same schemas as JSON messages
+func decodeRaw(frameType int, data []byte) (any, error) {
+	var raw any
+	if frameType != websocket.BinaryMessage {
+		err := json.Unmarshal(data, &raw)
+		return raw, err
+	}
+
+	if err := msgpack.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	asJson, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	raw = nil
+	err = json.Unmarshal(asJson, &raw)
+	return raw, err
+}
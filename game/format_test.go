@@ -0,0 +1,48 @@
+package game
+
+import (
+	"testing"
+)
+
+// benchmarkMessages are the messages encodeMessage is benchmarked with: a small one sent often, and a large one sent on joining
+var benchmarkMessages = map[string]any{
+	"chat message": Message{Type: ChatMessage, Content: ChatMessageContent{From: 2, Text: "good luck everyone!", SentAt: 1_700_000_000_000}},
+	"client details": Message{Type: ClientDetails, Content: ClientDetailsContent{
+		ClientId: 1,
+		Status:   InProgress,
+		Clients: []ClientContent{
+			{Id: 1, DisplayName: "Player 1", IconName: "cat", Alive: true},
+			{Id: 2, DisplayName: "Player 2", IconName: "dog", Alive: true},
+			{Id: 3, DisplayName: "Player 3", IconName: "fox", Alive: false},
+		},
+		CurrentTurnId:    2,
+		CurrentChallenge: "ing",
+		TurnEnd:          1_700_000_010_000,
+		MinPlayers:       2,
+		ChatHistory: []ChatMessageContent{
+			{From: 1, Text: "hello", SentAt: 1_700_000_000_000},
+			{From: 2, Text: "good luck everyone!", SentAt: 1_700_000_001_000},
+		},
+		RejoinToken: "8c6b1b7e-5f5c-4c4e-9d3a-2f1e0d9c8b7a",
+	}},
+}
+
+func BenchmarkEncodeMessage(b *testing.B) {
+	formats := map[string]messageFormat{"json": jsonFormat, "msgpack": msgpackFormat}
+	for formatName, format := range formats {
+		for messageName, message := range benchmarkMessages {
+			b.Run(formatName+"/"+messageName, func(b *testing.B) {
+				b.ReportAllocs()
+				var size int
+				for range b.N {
+					_, data, err := encodeMessage(message, format)
+					if err != nil {
+						b.Fatal(err)
+					}
+					size = len(data)
+				}
+				b.ReportMetric(float64(size), "bytes/msg")
+			})
+		}
+	}
+}
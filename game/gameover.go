@@ -0,0 +1,26 @@
+package game
+
+import "time"
+
+// announceGameOver broadcasts the GameOver message after LobbySettings.GameOverDelayMs, so that clients have a chance to handle
+// whatever ended the game (e.g. the TurnExpired for an answer submitted at the last moment) before they're told who won
+func (lobby *Lobby) announceGameOver(gameOver GameOverContent) {
+	lobby.pendingGameOver = gameOver
+	lobby.gameOverPending = true
+	if lobby.settings.GameOverDelayMs == 0 {
+		lobby.sendPendingGameOver()
+		return
+	}
+	lobby.gameOverDelay = time.After(time.Duration(lobby.settings.GameOverDelayMs) * time.Millisecond)
+}
+
+// sendPendingGameOver broadcasts the GameOver message held back by announceGameOver, if there is one
+// this is also called when a new game starts before the delay is up, so that the GameOver doesn't arrive in the middle of it
+func (lobby *Lobby) sendPendingGameOver() {
+	if !lobby.gameOverPending {
+		return
+	}
+	lobby.gameOverPending = false
+	lobby.gameOverDelay = nil
+	lobby.BroadcastMessage(Message{Type: GameOver, Content: lobby.pendingGameOver})
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// gameStatusNames are how each gameStatus is written in messages and API responses, rather than as a bare number
+var gameStatusNames = map[gameStatus]string{
+	WaitingForPlayers: "waiting_for_players",
+	InProgress:        "in_progress",
+	Over:              "over",
+}
+
+// name returns how the status is written in messages, e.g. "in_progress"
+func (status gameStatus) name() (string, error) {
+	name, ok := gameStatusNames[status]
+	if !ok {
+		return "", fmt.Errorf("unknown game status %d", int(status))
+	}
+	return name, nil
+}
+
+// parseGameStatus reads a status written by gameStatus.name
+func parseGameStatus(name string) (gameStatus, error) {
+	for status, candidate := range gameStatusNames {
+		if candidate == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown game status '%s'", name)
+}
+
+func (status gameStatus) MarshalJSON() ([]byte, error) {
+	name, err := status.name()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(name)
+}
+
+func (status *gameStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	parsed, err := parseGameStatus(name)
+	if err != nil {
+		return err
+	}
+	*status = parsed
+	return nil
+}
+
+// EncodeMsgpack writes the status as a string, the same as MarshalJSON, so that clients using either format see the same values
+func (status gameStatus) EncodeMsgpack(encoder *msgpack.Encoder) error {
+	name, err := status.name()
+	if err != nil {
+		return err
+	}
+	return encoder.EncodeString(name)
+}
+
+func (status *gameStatus) DecodeMsgpack(decoder *msgpack.Decoder) error {
+	name, err := decoder.DecodeString()
+	if err != nil {
+		return err
+	}
+	parsed, err := parseGameStatus(name)
+	if err != nil {
+		return err
+	}
+	*status = parsed
+	return nil
+}
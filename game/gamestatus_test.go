@@ -0,0 +1,70 @@
+package game
+
+import (
+	"encoding/json"
+	"github.com/vmihailenco/msgpack/v5"
+	"testing"
+)
+
+func TestGameStatusRoundTrip(t *testing.T) {
+	tests := []struct {
+		status gameStatus
+		name   string
+	}{
+		{status: WaitingForPlayers, name: "waiting_for_players"},
+		{status: InProgress, name: "in_progress"},
+		{status: Over, name: "over"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Run("json", func(t *testing.T) {
+				data, err := json.Marshal(test.status)
+				if err != nil {
+					t.Fatalf("failed to marshal %s: %v", test.status, err)
+				}
+				if want := `"` + test.name + `"`; string(data) != want {
+					t.Errorf("%s was marshaled as %s, want %s", test.status, data, want)
+				}
+
+				var decoded gameStatus
+				if err = json.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("failed to unmarshal %s: %v", data, err)
+				}
+				if decoded != test.status {
+					t.Errorf("%s was unmarshaled as %s, want %s", data, decoded, test.status)
+				}
+			})
+
+			t.Run("msgpack", func(t *testing.T) {
+				data, err := msgpack.Marshal(test.status)
+				if err != nil {
+					t.Fatalf("failed to marshal %s: %v", test.status, err)
+				}
+				var name string
+				if err = msgpack.Unmarshal(data, &name); err != nil || name != test.name {
+					t.Errorf("%s was marshaled as %q (%v), want %q", test.status, name, err, test.name)
+				}
+
+				var decoded gameStatus
+				if err = msgpack.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("failed to unmarshal %x: %v", data, err)
+				}
+				if decoded != test.status {
+					t.Errorf("%x was unmarshaled as %s, want %s", data, decoded, test.status)
+				}
+			})
+		})
+	}
+}
+
+func TestGameStatusUnknown(t *testing.T) {
+	if _, err := json.Marshal(gameStatus(-1)); err == nil {
+		t.Error("marshaled an unknown status without an error")
+	}
+
+	var decoded gameStatus
+	if err := json.Unmarshal([]byte(`"paused"`), &decoded); err == nil {
+		t.Errorf(`unmarshaled "paused" as %s, want an error`, decoded)
+	}
+}
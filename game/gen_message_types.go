@@ -0,0 +1,142 @@
+//go:build ignore
+
+// gen_message_types generates message_types.go, which describes every message type for the GET /api/message-types endpoint
+// descriptions are taken from the comments on the constants in message.go, and the direction of each message type is
+// worked out from the code: types with a handler in registerHandlers are sent by clients, and types used to build a Message
+// anywhere else in the package are sent by the server
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type messageTypeConst struct {
+	name        string
+	value       string
+	description string
+}
+
+func main() {
+	fileSet := token.NewFileSet()
+	packages, err := parser.ParseDir(fileSet, ".", func(info os.FileInfo) bool {
+		return info.Name() != "message_types.go" && !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pkg, ok := packages["game"]
+	if !ok {
+		log.Fatal("package game not found")
+	}
+
+	consts := findMessageTypes(pkg.Files["message.go"])
+	isMessageType := make(map[string]bool, len(consts))
+	for _, c := range consts {
+		isMessageType[c.name] = true
+	}
+
+	fromClient := make(map[string]bool)
+	fromServer := make(map[string]bool)
+	for _, file := range pkg.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncDecl:
+				// the keys of the map in registerHandlers are the types clients can send
+				if n.Name.Name == "registerHandlers" {
+					ast.Inspect(n.Body, func(node ast.Node) bool {
+						if kv, ok := node.(*ast.KeyValueExpr); ok {
+							if ident, ok := kv.Key.(*ast.Ident); ok && isMessageType[ident.Name] {
+								fromClient[ident.Name] = true
+							}
+						}
+						return true
+					})
+					return false
+				}
+			case *ast.KeyValueExpr:
+				// anything else building a Message{Type: X} is the server sending X
+				key, ok := n.Key.(*ast.Ident)
+				if !ok || key.Name != "Type" {
+					return true
+				}
+				if value, ok := n.Value.(*ast.Ident); ok && isMessageType[value.Name] {
+					fromServer[value.Name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by \"go run gen_message_types.go\"; DO NOT EDIT.\n\n")
+	buf.WriteString("package game\n\n")
+	buf.WriteString("// MessageTypes describes every type of message, keyed by the type's value\n")
+	buf.WriteString("var MessageTypes = map[string]MessageTypeInfo{\n")
+	for _, c := range consts {
+		var direction string
+		switch {
+		case fromClient[c.name] && fromServer[c.name]:
+			direction = "BothDirections"
+		case fromClient[c.name]:
+			direction = "ClientToServer"
+		default:
+			direction = "ServerToClient"
+		}
+		fmt.Fprintf(&buf, "\t%q: {Direction: %s, Description: %q},\n", c.value, direction, c.description)
+	}
+	buf.WriteString("}\n")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = os.WriteFile("message_types.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// findMessageTypes returns the constants in the const block declaring the messageType values, in declaration order
+func findMessageTypes(file *ast.File) []messageTypeConst {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST || len(genDecl.Specs) == 0 {
+			continue
+		}
+
+		// the block of message types is the one whose first constant is declared as a messageType
+		first := genDecl.Specs[0].(*ast.ValueSpec)
+		if ident, ok := first.Type.(*ast.Ident); !ok || ident.Name != "messageType" {
+			continue
+		}
+
+		var consts []messageTypeConst
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			value, err := strconv.Unquote(valueSpec.Values[0].(*ast.BasicLit).Value)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			consts = append(consts, messageTypeConst{
+				name:        valueSpec.Names[0].Name,
+				value:       value,
+				description: strings.TrimSpace(valueSpec.Comment.Text()),
+			})
+		}
+		return consts
+	}
+
+	log.Fatal("no messageType constants found in message.go")
+	return nil
+}
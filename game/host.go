@@ -0,0 +1,63 @@
+package game
+
+import "slices"
+
+// isHost returns whether the client with the given id is the lobby's host, who is the only one allowed to start or restart the game
+func (lobby *Lobby) isHost(clientId int) bool {
+	return clientId == lobby.hostId
+}
+
+// claimHostIfVacant makes the client the host if the lobby doesn't have one, e.g. because they are the first to join
+func (lobby *Lobby) claimHostIfVacant(client *Client) {
+	if lobby.hostId == 0 {
+		lobby.hostId = client.id
+		lobby.logger.Info("client is the host", "client", client.String())
+	}
+}
+
+// denyPermission tells the client they aren't allowed to do what they asked, since only the host can
+func (lobby *Lobby) denyPermission(message Message) {
+	lobby.logger.Info("ignoring host-only message", "type", message.Type, "client", lobby.clients[message.From].String())
+	lobby.SendToClient(message.From, Message{Type: PermissionDenied, Content: PermissionDeniedContent{MessageType: message.Type}})
+}
+
+// promoteNextHost hands the host role to the connected client with the lowest id, after the host has left
+func (lobby *Lobby) promoteNextHost() {
+	lobby.hostId = 0
+	ids := make([]int, 0, len(lobby.clients))
+	for id, client := range lobby.clients {
+		if client.seatOf == nil { // seats can't send messages of their own, only their device can
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	lobby.setHost(slices.Min(ids))
+}
+
+func (lobby *Lobby) setHost(clientId int) {
+	lobby.hostId = clientId
+	lobby.logger.Info("host transferred", "client", lobby.clients[clientId].String())
+	lobby.BroadcastMessage(Message{Type: TransferHost, Content: TransferHostContent{HostId: clientId}})
+}
+
+// onTransferHost lets the host hand the role to another client
+func (lobby *Lobby) onTransferHost(message Message) {
+	if !lobby.isHost(message.From) {
+		lobby.denyPermission(message)
+		return
+	}
+
+	newHostId, err := DecodeContent[int](message)
+	if err != nil {
+		return
+	}
+	newHost, exists := lobby.clients[newHostId]
+	if !exists || newHost.seatOf != nil || newHostId == message.From {
+		return
+	}
+
+	lobby.setHost(newHostId)
+}
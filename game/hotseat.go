@@ -0,0 +1,91 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+)
+
+// in hot seat lobbies, every player shares a single device (and so a single websocket connection)
+// the first client to connect is that device. it doesn't play itself, instead the lobby gives it LobbySettings.HotSeatPlayers
+// seats, which are clients without a connection of their own that take turns like any other client
+// anything sent to a seat is sent to its device, and answers from the device are played by whichever seat's turn it is
+
+// joinHotSeatDevice handles a client connecting to a hot seat lobby
+// the first one becomes the device the seats are played on, anyone else can only watch
+func (lobby *Lobby) joinHotSeatDevice(joiningClient *Client) {
+	if lobby.hotSeatDevice == nil {
+		lobby.hotSeatDevice = joiningClient
+		for seatNumber := 1; seatNumber <= lobby.settings.HotSeatPlayers; seatNumber++ {
+			seatId := lobby.GetNextClientId()
+			seat := &Client{
+				id:       seatId,
+				iconName: lobby.GetDefaultIconName(seatId),
+				lobby:    lobby,
+				seatOf:   joiningClient,
+			}
+			seat.setDisplayName(fmt.Sprintf("Seat %d", seatNumber))
+			lobby.clients[seat.id] = seat
+			if lobby.status != InProgress {
+				lobby.aliveClients = append(lobby.aliveClients, seat)
+			}
+		}
+		lobby.logger.Info("client is the hot seat device", "client", joiningClient.String(), "seats", lobby.settings.HotSeatPlayers)
+	}
+
+	lobby.claimHostIfVacant(joiningClient)
+	joiningClient.write <- Message{Type: ClientDetails, Content: lobby.BuildClientDetails(joiningClient.id)}
+
+	lobby.clients[joiningClient.id] = joiningClient
+	lobby.updateClientCount()
+	lobby.BroadcastMessage(Message{Type: ClientJoined, Content: ClientJoinedContent{
+		ClientId:    joiningClient.id,
+		DisplayName: joiningClient.displayName,
+		IconName:    joiningClient.iconName,
+		Alive:       false, // only the seats play
+		HostId:      lobby.hostId,
+	}})
+}
+
+// leaveHotSeatDevice removes the seats of a device which has disconnected, since nobody can play them anymore
+func (lobby *Lobby) leaveHotSeatDevice(device *Client) {
+	lobby.hotSeatDevice = nil
+	for _, c := range lobby.clients {
+		if c.seatOf == device {
+			lobby.onClientLeave(c)
+		}
+	}
+}
+
+// routeHotSeatMessage attributes gameplay messages from the hot seat device to the seat whose turn it is
+func (lobby *Lobby) routeHotSeatMessage(message Message) Message {
+	if lobby.hotSeatDevice == nil || message.From != lobby.hotSeatDevice.id || lobby.status != InProgress {
+		return message
+	}
+
+	switch message.Type {
+	case AnswerPreview, SubmitAnswer, DoubleDown:
+		message.From = lobby.aliveClients[lobby.turnIndex].id
+	}
+	return message
+}
+
+// getSeatIds returns the ids of the seats played on the given client's device, if it is the hot seat device
+func (lobby *Lobby) getSeatIds(clientId int) []int {
+	if lobby.hotSeatDevice == nil || lobby.hotSeatDevice.id != clientId {
+		return nil
+	}
+
+	var seatIds []int
+	for _, c := range lobby.clients {
+		if c.seatOf == lobby.hotSeatDevice {
+			seatIds = append(seatIds, c.id)
+		}
+	}
+	slices.Sort(seatIds)
+	return seatIds
+}
+
+// isPlayer returns if the client takes turns. in hot seat lobbies only the seats do
+func (lobby *Lobby) isPlayer(client *Client) bool {
+	return lobby.settings.GameMode != HotSeatGameMode || client.seatOf != nil
+}
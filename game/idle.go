@@ -0,0 +1,31 @@
+package game
+
+import "time"
+
+const idleTimeout = 10 * time.Minute       // how long a lobby can wait for players without any activity before it is closed
+const idleWarningBefore = 60 * time.Second // how long before closing an idle lobby the clients are warned
+
+// resetIdleTimer restarts the countdown to closing the lobby for inactivity. lobbies only time out while waiting for players
+func (lobby *Lobby) resetIdleTimer() {
+	lobby.idleExpired = nil
+	if lobby.status != WaitingForPlayers {
+		lobby.idleWarning = nil
+		return
+	}
+	lobby.idleWarning = time.After(idleTimeout - idleWarningBefore)
+}
+
+// onIdleWarning lets the clients know the lobby is about to be closed, giving them a chance to start the game (or do anything else)
+func (lobby *Lobby) onIdleWarning() {
+	lobby.logger.Info("lobby is idle, closing soon unless something happens",
+		"idleMs", (idleTimeout - idleWarningBefore).Milliseconds(), "closingInMs", idleWarningBefore.Milliseconds())
+	lobby.idleWarning = nil
+	lobby.idleExpired = time.After(idleWarningBefore)
+	lobby.BroadcastMessage(Message{Type: InactivityWarning, Content: InactivityWarningContent{SecondsRemaining: int(idleWarningBefore.Seconds())}})
+}
+
+// onIdleExpired tells the clients the lobby is being closed. the lobby ends once this returns
+func (lobby *Lobby) onIdleExpired() {
+	lobby.logger.Info("lobby closed for being idle", "idleMs", idleTimeout.Milliseconds())
+	lobby.BroadcastShutdown(IdleTimeoutShutdown)
+}
@@ -0,0 +1,68 @@
+package game
+
+// IsKicked returns whether the rejoin token belongs to a client who was kicked from the lobby
+// like ClaimRejoinToken, this is safe to call from any goroutine, so that kicked clients can be turned away before they join
+func (lobby *Lobby) IsKicked(rejoinToken string) bool {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	_, kicked := lobby.kickedClients[rejoinToken]
+	return kicked
+}
+
+func (lobby *Lobby) addKickedClient(rejoinToken string) {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	lobby.kickedClients[rejoinToken] = struct{}{}
+}
+
+// onKickClient lets the host remove a client from the lobby. the client's connection is closed once they've been told why
+func (lobby *Lobby) onKickClient(message Message) {
+	if !lobby.isHost(message.From) {
+		lobby.denyPermission(message)
+		return
+	}
+
+	targetId, err := DecodeContent[int](message)
+	if err != nil {
+		return
+	}
+	target, exists := lobby.clients[targetId]
+	if !exists || target.seatOf != nil || targetId == message.From {
+		return
+	}
+
+	lobby.logger.Info("client kicked", "host", lobby.clients[message.From].String(), "client", target.String())
+	lobby.kickClient(target, KickedByHostReason)
+}
+
+// onRateLimitExceeded kicks a client who has had too many messages dropped for sending them too quickly, see Client.Read
+func (lobby *Lobby) onRateLimitExceeded(client *Client) {
+	// the client may have left while the lobby was busy
+	if lobby.clients[client.id] != client {
+		return
+	}
+
+	lobby.logger.Warn("kicking client for exceeding the message rate limit", "client", client.String())
+	lobby.kickClient(client, RateLimitedKickReason)
+}
+
+// kickClient removes the client from the lobby for good, so they can't rejoin with their rejoin token
+func (lobby *Lobby) kickClient(target *Client, reason kickReason) {
+	lobby.addKickedClient(target.rejoinToken)
+	lobby.deleteRejoinToken(target.rejoinToken)
+
+	// the kicked client is told too, so they know why they're being disconnected. see Client.Write
+	lobby.BroadcastMessage(Message{Type: ClientKicked, Content: ClientKickedContent{ClientId: target.id, Reason: reason}})
+
+	delete(lobby.clients, target.id)
+	delete(lobby.chatTokens, target.id)
+	delete(lobby.lastSyncRequest, target.id)
+	delete(lobby.lastSuggestionTurn, target.id)
+	lobby.updateClientCount()
+	lobby.removeClient(target)
+	if lobby.isHost(target.id) {
+		lobby.promoteNextHost()
+	}
+}
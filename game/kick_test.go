@@ -0,0 +1,29 @@
+package game
+
+import (
+	"testing"
+)
+
+// joinTestClient joins a client without a connection to the lobby, as its goroutine would. messages sent to it are left in its write channel
+func joinTestClient(lobby *Lobby) *Client {
+	client := NewClient(lobby.GetNextClientId(), nil, lobby)
+	client.write = make(chan Message, 100)
+	lobby.onClientJoin(client)
+	return client
+}
+
+func TestKickedHostIsReplaced(t *testing.T) {
+	lobby := newTestLobby(DefaultLobbySettings())
+	host := joinTestClient(lobby)
+	other := joinTestClient(lobby)
+	if !lobby.isHost(host.id) {
+		t.Fatalf("%s is the host, want %s", lobby.clients[lobby.hostId], host)
+	}
+
+	// the host can't kick themselves, but they can be kicked for flooding the lobby
+	lobby.onRateLimitExceeded(host)
+
+	if !lobby.isHost(other.id) {
+		t.Errorf("the host is client %d after the host was kicked, want %s", lobby.hostId, other)
+	}
+}
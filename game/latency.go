@@ -0,0 +1,32 @@
+package game
+
+import (
+	"strconv"
+	"time"
+)
+
+const maxLatencyAllowance = 3 * time.Second // the most extra time a client's turn can be given for their connection's round-trip time
+
+// recordPong measures the round-trip time of the ping which the pong answers
+// pings carry the time they were sent as their payload, which the client's pong echoes back. called by the Read goroutine
+func (c *Client) recordPong(payload string) {
+	sentAt, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return // an unsolicited pong, which isn't answering any ping
+	}
+	if rtt := time.Since(time.UnixMilli(sentAt)); rtt >= 0 {
+		c.rttMs.Store(rtt.Milliseconds())
+	}
+}
+
+// getLatencyAllowance returns the extra time the client is given for their turn when LobbySettings.AdaptiveTimingEnabled is set,
+// so that players on slow connections aren't cut off: twice their round-trip time, up to maxLatencyAllowance
+func (lobby *Lobby) getLatencyAllowance(client *Client) time.Duration {
+	if !lobby.settings.AdaptiveTimingEnabled {
+		return 0
+	}
+	if client.seatOf != nil {
+		client = client.seatOf // seats have no connection of their own, their device's is what matters
+	}
+	return min(2*time.Duration(client.rttMs.Load())*time.Millisecond, maxLatencyAllowance)
+}
@@ -0,0 +1,35 @@
+package game
+
+// resetLives gives every player LobbySettings.StartingLives for the new game
+// teams lobbies share lives between each team's players instead, see Team
+func (lobby *Lobby) resetLives() {
+	if lobby.isTeamMode() {
+		lobby.lives = nil
+		return
+	}
+	lobby.lives = make(map[int]int, len(lobby.aliveClients))
+	for _, c := range lobby.aliveClients {
+		lobby.lives[c.id] = lobby.settings.StartingLives
+		lobby.broadcastLives(c)
+	}
+}
+
+// loseLife takes a life from the client whose turn ran out, returning whether they have any left (and so stay in the game)
+func (lobby *Lobby) loseLife(client *Client) bool {
+	lobby.lives[client.id] = max(lobby.lives[client.id]-1, 0)
+	lobby.broadcastLives(client)
+	return lobby.lives[client.id] > 0
+}
+
+// restoreLife gives back a life the client has lost, for an accepted answer, if the lobby has AnswerRestoresLife enabled
+func (lobby *Lobby) restoreLife(client *Client) {
+	if !lobby.settings.AnswerRestoresLife || lobby.isTeamMode() || lobby.lives[client.id] >= lobby.settings.StartingLives {
+		return
+	}
+	lobby.lives[client.id]++
+	lobby.broadcastLives(client)
+}
+
+func (lobby *Lobby) broadcastLives(client *Client) {
+	lobby.BroadcastMessage(Message{Type: LivesUpdated, Content: LivesUpdatedContent{ClientId: client.id, RemainingLives: lobby.lives[client.id]}})
+}
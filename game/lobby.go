@@ -1,17 +1,20 @@
 package game
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jhshelnu/wordcraft/icons"
+	"github.com/jhshelnu/wordcraft/metrics"
+	"github.com/jhshelnu/wordcraft/storage"
 	"github.com/jhshelnu/wordcraft/words"
-	"log"
+	"log/slog"
 	"maps"
-	"os"
 	"runtime/debug"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,49 +32,236 @@ const (
 )
 
 type Lobby struct {
-	Id uuid.UUID // the unique identifier for this lobby
+	Id        uuid.UUID // the unique identifier for this lobby
+	CreatedAt time.Time // when the lobby was created
+	Code      string    // a short, human-readable alias for Id, e.g. "WXYZ12"
 
-	logger *log.Logger
+	logger   *slog.Logger   // the lobby's logger, with its id attached to every entry (see WithLobbyId)
+	settings LobbySettings  // the options this lobby was created with
+	wordSet  *words.WordSet // the custom word list answers are checked against and challenges are taken from, or nil for the default one (see wordList)
+	password string         // clients must provide this to join the lobby, see CheckPassword. empty if the lobby is public
 
-	join  chan *Client // channel for new clients to join the lobby
-	leave chan *Client // channel for existing clients to leave the lobby
-	read  chan Message // channel for existing clients to send messages for the Lobby to read
+	join          chan *Client                 // channel for new clients to join the lobby
+	leave         chan *Client                 // channel for existing clients to leave the lobby
+	read          chan Message                 // channel for existing clients to send messages for the Lobby to read
+	stateRequests chan chan LobbyStateSnapshot // receives requests for a snapshot of the lobby's state, which is sent back on the given channel. see RequestState
+
+	handlers map[messageType]func(Message) // the handler for each type of message clients can send, see registerHandlers
 
 	iconNames []string // a slice of icon file names (shuffled for each lobby)
 
 	// todo: consider refactoring these fields into a game state struct for better code separation
-	clients           map[int]*Client  // all clients in the lobby, indexed by their id
-	aliveClients      []*Client        // all clients in the lobby who are not out
-	status            gameStatus       // the status of the game, indicates if its started, in progress, etc
-	turnIndex         int              // the index in aliveClients of whose turn it is
-	turnRounds        int              // how many times the turn has changed to the first player (lowest client id)
-	currentChallenge  string           // the current challenge string for clientsTurn
-	currentAnswerPrev string           // preview of what the client whose turn it is has typed so far
-	currentTurnEnd    int64            // when the current turn ends, in milliseconds from the unix epoch (UTC)
-	turnExpired       <-chan time.Time // a (read-only) channel which produces a single boolean value once the client has run out of time
-	winnersName       string           // the name of the winning client (captured at the moment they won) this is for new clients joining after the game
+	clients           map[int]*Client     // all clients in the lobby, indexed by their id
+	aliveClients      []*Client           // all clients in the lobby who are not out
+	status            gameStatus          // the status of the game, indicates if its started, in progress, etc
+	turnIndex         int                 // the index in aliveClients of whose turn it is
+	turnRounds        int                 // how many times the turn has changed to the first player (lowest client id)
+	turnCount         int                 // how many turns have been taken this game
+	currentChallenge  string              // the current challenge string for clientsTurn
+	currentAnswerPrev string              // preview of what the client whose turn it is has typed so far
+	currentTurnEnd    int64               // when the current turn ends, in milliseconds from the unix epoch (UTC). wall clock time, only for displaying on clients
+	turnDeadline      time.Time           // when the current turn ends, with a monotonic clock reading so it is unaffected by wall clock adjustments
+	turnExpired       <-chan time.Time    // a (read-only) channel which produces a single boolean value once the client has run out of time
+	gracePeriodEnded  <-chan time.Time    // a (read-only) channel which produces a single value once the client has had time to read the challenge
+	acceptedAnswers   []string            // every answer accepted so far this game
+	usedAnswers       map[string]struct{} // the set of answers accepted so far this game, since each answer can only be used once (see LobbySettings.AllowReuse)
+	winnersName       string              // the name of the winning client (captured at the moment they won) this is for new clients joining after the game
+	startedAt         time.Time           // when the current (or last) game started
+	startingPlayers   int                 // how many clients were alive at the start of the current (or last) game
+	endedAt           time.Time           // when the last game ended
+
+	clientCount atomic.Int32 // mirrors len(clients), so it can be read outside the lobby goroutine (see ClientCount)
+	statusValue atomic.Int32 // mirrors status, so it can be read outside the lobby goroutine (see Status)
+	active      atomic.Bool  // whether the lobby goroutine is running, i.e. StartLobby has been called and the lobby hasn't started ending (see IsActive)
+
+	lastClientId  int                 // the id of the last client which connected (used to increment Client.id's as they join the lobby)
+	rejoinTokens  map[string]int      // the ids of reconnecting clients, keyed by their rejoin token (see ClaimRejoinToken)
+	kickedClients map[string]struct{} // the rejoin tokens of clients the host has kicked, who can't come back (see IsKicked)
+	finalists     map[string]struct{} // for tournament finals, the rejoin tokens of the winners who can join (see RegisterFinalist)
+	clientIdMutex sync.Mutex          // enforces thread-safe access to the nextClientId, rejoinTokens, kickedClients and finalists
+
+	challengeStats      map[string]ChallengeStats // how the answers to each challenge of this game fared, keyed by challenge (see ChallengeStats)
+	challengeStatsMutex sync.Mutex                // enforces thread-safe access to challengeStats, since it's read outside the lobby goroutine
+
+	lobbyOver           chan uuid.UUID              // channel that lets this lobby notify the main thread that this lobby has completed. This allows the Lobby to get GC'ed
+	done                chan struct{}               // closed once the lobby has ended, so goroutines outside the lobby stop waiting on it
+	validationResults   chan validationResult       // receives answers once they've been checked against the word list, see validateAnswer
+	nextRound           chan uuid.UUID              // for tournament lobbies, receives the id of the lobby hosting the next round once the result has been reported
+	difficultyCapped    bool                        // whether the clients have been told about a DifficultyCapped this game
+	doubleDownActive    bool                        // whether the client whose turn it is has doubled down this turn
+	telemetry           lobbyTelemetry              // activity counters, periodically logged and reset by logTelemetry
+	forks               chan uuid.UUID              // receives the ids of lobbies forked from this one, to offer to the alive clients
+	hotSeatDevice       *Client                     // in hot seat lobbies, the client whose device the seats are played on (nil until it connects)
+	chatHistory         recent[ChatMessageContent]  // the most recent chat messages, sent to clients when they join
+	chatTokens          map[int]*rateLimiter        // limits how often each client can chat, indexed by client id
+	announcements       recent[AnnouncementContent] // the host's most recent announcements, sent to clients when they join
+	lastSyncRequest     map[int]time.Time           // when each client last had the lobby's state sent again, indexed by client id (see onSyncRequest)
+	idleWarning         <-chan time.Time            // fires when the lobby has been idle for long enough to warn the clients, see resetIdleTimer
+	idleExpired         <-chan time.Time            // fires once the lobby has been idle for too long, after the clients were warned
+	longestStreakRecord int                         // the longest streak any client has had this game
+	longestStreakHolder int                         // the id of the client who set longestStreakRecord
+	clientAnswerHistory map[int]map[string]struct{} // the answers each client has had accepted this game, indexed by client id (see LobbySettings.NoRepeat)
+	challengeVault      []string                    // the usable challenges from LobbySettings.ChallengeVault, see loadChallengeVault
+	vaultIndex          int                         // how many challenges have been taken from challengeVault this game
+	turnLimit           time.Duration               // how long the current turn lasts in total
+	reconnecting        map[int]*ReconnectingClient // alive clients who disconnected mid-game, keyed by id, whose place is held for a while in case they reconnect
+	reconnectExpired    chan *ReconnectingClient    // receives reconnecting clients once their grace period is over, see holdForReconnect
+	rateLimitExceeded   chan *Client                // receives clients who have had too many messages dropped by their rate limiter, see Client.Read
+	validationPending   map[int]string              // answers sent to validateAnswer which haven't been handled yet, keyed by the id of the client who submitted them
+	pendingPromotion    []*Client                   // spectators who have asked to play in the next game, see onPromoteObserver
+	clockSyncTicker     *time.Ticker                // ticks every clockSyncInterval during a turn, reset whenever the turn changes
+	clockSync           <-chan time.Time            // the channel of clockSyncTicker, or nil when no turn is in progress
+	awaitingChallenge   bool                        // in manual challenge mode, whether the turn is waiting on the host to choose its challenge, see awaitChallenge
+	challengeTimeout    <-chan time.Time            // fires if the host takes too long to choose the challenge, or nil when not awaiting one
+	store               storage.Store               // where the results of finished games are saved, or nil if they aren't kept
+	gameResults         []storage.GameResult        // the results of games finished in this lobby, saved to store once the lobby ends
+	hostId              int                         // the id of the client who can start and restart the game, see claimHostIfVacant. 0 if there's nobody to be host
+	gameOverPending     bool                        // whether pendingGameOver is waiting to be broadcast, see announceGameOver
+	pendingGameOver     GameOverContent             // the GameOver message held back until gameOverDelay fires
+	aliveTeams          []*Team                     // in teams lobbies, the two teams playing the current game, see resetTeams
+	lives               map[int]int                 // how many more times each player's turn can run out before they're out, indexed by client id
+	lastSubmitTime      map[int]time.Time           // when each client last submitted an answer this turn, indexed by client id (see LobbySettings.MinAnswerIntervalMs)
+	lastSuggestionTurn  map[int]int                 // the turnCount of each client's last word_suggestion, indexed by client id (see onWordSuggestion)
+	gameOverDelay       <-chan time.Time            // fires once the GameOver message can be broadcast, or nil when there isn't one pending
+}
 
-	lastClientId  int        // the id of the last client which connected (used to increment Client.id's as they join the lobby)
-	clientIdMutex sync.Mutex // enforces thread-safe access to the nextClientId
+// NewLobby creates a lobby with the given settings. if password isn't empty, clients can only join the lobby by providing it
+// wordSet is the lobby's custom word list, or nil to use the default one
+func NewLobby(lobbyOver chan uuid.UUID, settings LobbySettings, password string, store storage.Store, wordSet *words.WordSet) *Lobby {
+	Id := uuid.New()
+	logger := WithLobbyId(Logger, Id)
+
+	lobby := &Lobby{
+		logger:            logger,
+		settings:          settings,
+		password:          password,
+		store:             store,
+		wordSet:           wordSet,
+		Id:                Id,
+		CreatedAt:         time.Now(),
+		Code:              NewLobbyCode(),
+		join:              make(chan *Client),
+		leave:             make(chan *Client),
+		read:              make(chan Message),
+		iconNames:         icons.GetShuffledIconNames(),
+		status:            WaitingForPlayers,
+		clients:           make(map[int]*Client),
+		turnIndex:         -1,
+		lobbyOver:         lobbyOver,
+		done:              make(chan struct{}),
+		nextRound:         make(chan uuid.UUID, 1),
+		forks:             make(chan uuid.UUID),
+		reconnecting:      make(map[int]*ReconnectingClient),
+		reconnectExpired:  make(chan *ReconnectingClient),
+		rateLimitExceeded: make(chan *Client),
+		stateRequests:     make(chan chan LobbyStateSnapshot),
+		rejoinTokens:      make(map[string]int),
+		kickedClients:     make(map[string]struct{}),
+		finalists:         make(map[string]struct{}),
+		validationResults: make(chan validationResult, 16),
+		validationPending: make(map[int]string),
+		chatHistory:       newRecent[ChatMessageContent](chatHistorySize),
+		chatTokens:        make(map[int]*rateLimiter),
+		announcements:     newRecent[AnnouncementContent](announcementHistorySize),
+		challengeStats:    make(map[string]ChallengeStats),
+	}
+	lobby.registerHandlers()
+	lobby.challengeVault = lobby.loadChallengeVault()
 
-	lobbyOver chan uuid.UUID // channel that lets this lobby notify the main thread that this lobby has completed. This allows the Lobby to get GC'ed
+	return lobby
 }
 
-func NewLobby(lobbyOver chan uuid.UUID) *Lobby {
-	Id := uuid.New()
-	logger := log.New(os.Stdout, fmt.Sprintf("Lobby [%s]: ", Id), log.Lshortfile|log.Lmsgprefix)
+// registerHandlers sets up which method handles each type of message sent by clients
+// to handle a new type of message, add its handler here
+func (lobby *Lobby) registerHandlers() {
+	lobby.handlers = map[messageType]func(Message){
+		StartGame:           lobby.onStartGame,
+		RestartGame:         lobby.onRestartGame,
+		AnswerPreview:       lobby.onAnswerPreview,
+		SubmitAnswer:        lobby.onAnswerSubmitted,
+		NameChange:          lobby.onNameChange,
+		DoubleDown:          lobby.onDoubleDown,
+		ChatMessage:         lobby.onChatMessage,
+		RequestCurrentState: lobby.onRequestCurrentState,
+		Negotiate:           lobby.onNegotiate,
+		TransferHost:        lobby.onTransferHost,
+		KickClient:          lobby.onKickClient,
+		ProvideChallenge:    lobby.onProvideChallenge,
+		PromoteObserver:     lobby.onPromoteObserver,
+		SyncRequest:         lobby.onSyncRequest,
+		WordSuggestion:      lobby.onWordSuggestion,
+		Announcement:        lobby.onAnnouncement,
+	}
+}
+
+// Settings returns the options this lobby was created with. These never change, so this is safe to call from any goroutine
+func (lobby *Lobby) Settings() LobbySettings {
+	return lobby.settings
+}
+
+// WordSet returns the lobby's custom word list, or nil if it uses the default one. Like Settings, this never changes
+func (lobby *Lobby) WordSet() *words.WordSet {
+	return lobby.wordSet
+}
+
+// wordList returns the word set the lobby's answers are checked against and challenges are taken from
+func (lobby *Lobby) wordList() *words.WordSet {
+	if lobby.wordSet != nil {
+		return lobby.wordSet
+	}
+	return words.Default()
+}
+
+// Password returns the password clients must provide to join the lobby, or "" if it is public
+func (lobby *Lobby) Password() string {
+	return lobby.password
+}
+
+// IsPasswordProtected returns whether clients need a password to join the lobby
+func (lobby *Lobby) IsPasswordProtected() bool {
+	return lobby.password != ""
+}
+
+// CheckPassword returns whether password lets a client join the lobby. any password is accepted for public lobbies
+func (lobby *Lobby) CheckPassword(password string) bool {
+	return !lobby.IsPasswordProtected() || subtle.ConstantTimeCompare([]byte(password), []byte(lobby.password)) == 1
+}
+
+// ClientCount returns how many clients are in the lobby. Unlike len(lobby.clients), this is safe to call from any goroutine
+func (lobby *Lobby) ClientCount() int {
+	return int(lobby.clientCount.Load())
+}
+
+// updateClientCount brings clientCount, and the number of clients connected across all lobbies, up to date after clients has changed
+func (lobby *Lobby) updateClientCount() {
+	count := int32(len(lobby.clients))
+	metrics.ClientsConnected.Add(float64(count - lobby.clientCount.Swap(count)))
+}
+
+// Status returns the status of the game. Unlike lobby.status, this is safe to call from any goroutine
+func (lobby *Lobby) Status() gameStatus {
+	return gameStatus(lobby.statusValue.Load())
+}
 
-	return &Lobby{
-		logger:    logger,
-		Id:        Id,
-		join:      make(chan *Client),
-		leave:     make(chan *Client),
-		read:      make(chan Message),
-		iconNames: icons.GetShuffledIconNames(),
-		status:    WaitingForPlayers,
-		clients:   make(map[int]*Client),
-		turnIndex: -1,
-		lobbyOver: lobbyOver,
+// IsActive returns whether the lobby is running. It becomes false as soon as the lobby starts ending, before it's been removed from the server
+// like Status, this is safe to call from any goroutine
+func (lobby *Lobby) IsActive() bool {
+	return lobby.active.Load()
+}
+
+// setStatus changes the status of the game, keeping statusValue in sync with it
+// the start and end of each game are recorded too, see buildFinalStandings and recordGameResult
+func (lobby *Lobby) setStatus(status gameStatus) {
+	lobby.status = status
+	lobby.statusValue.Store(int32(status))
+
+	switch status {
+	case InProgress:
+		lobby.startedAt = time.Now()
+		lobby.startingPlayers = len(lobby.aliveClients)
+	case Over:
+		lobby.endedAt = time.Now()
+		metrics.GamesPlayed.Inc()
 	}
 }
 
@@ -88,67 +278,168 @@ func (lobby *Lobby) GetDefaultIconName(id int) string {
 }
 
 func (lobby *Lobby) StartLobby() {
+	lobby.active.Store(true)
+	metrics.ActiveLobbies.Inc()
 	defer lobby.EndLobby()
+	defer func() {
+		metrics.ActiveLobbies.Dec()
+		// nobody is left to read the clients leaving once the lobby is over, so they stop counting as connected here
+		metrics.ClientsConnected.Sub(float64(lobby.clientCount.Load()))
+	}()
 	defer func() {
 		if r := recover(); r != nil {
-			lobby.logger.Printf("Encountered fatal error: %v\n%s", r, debug.Stack())
+			lobby.logger.Error("encountered fatal error", "error", r, "stack", string(debug.Stack()))
 		}
 	}()
 
+	lobby.resetIdleTimer()
+	telemetryTicker := time.NewTicker(telemetryInterval)
+	defer telemetryTicker.Stop()
+	defer lobby.stopClockSync()
+
 	for {
 		select {
 		case client := <-lobby.join:
 			lobby.onClientJoin(client)
+			lobby.resetIdleTimer()
 		case client := <-lobby.leave:
 			lobby.onClientLeave(client)
-			if len(lobby.clients) == 0 {
-				lobby.logger.Printf("All clients have disconnected. Goodbye.")
+			if len(lobby.clients) == 0 && len(lobby.reconnecting) == 0 {
+				lobby.logger.Info("all clients have disconnected, closing the lobby")
+				return
+			}
+		case reconnectingClient := <-lobby.reconnectExpired:
+			lobby.onReconnectExpired(reconnectingClient)
+			if len(lobby.clients) == 0 && len(lobby.reconnecting) == 0 {
+				lobby.logger.Info("all clients have disconnected, closing the lobby")
 				return
 			}
 		case message := <-lobby.read:
 			lobby.onMessage(message)
+			lobby.resetIdleTimer()
+		case client := <-lobby.rateLimitExceeded:
+			lobby.onRateLimitExceeded(client)
 		case <-lobby.turnExpired:
 			lobby.onTurnExpired()
+		case <-lobby.gracePeriodEnded:
+			lobby.onGracePeriodEnded()
+		case result := <-lobby.validationResults:
+			lobby.onAnswerValidated(result)
+		case nextLobbyId := <-lobby.nextRound:
+			lobby.onNextRound(nextLobbyId)
+		case forkLobbyId := <-lobby.forks:
+			lobby.onForkAvailable(forkLobbyId)
+		case response := <-lobby.stateRequests:
+			lobby.onStateRequest(response)
+		case <-lobby.idleWarning:
+			lobby.onIdleWarning()
+		case <-lobby.idleExpired:
+			lobby.onIdleExpired()
+			return
+		case <-telemetryTicker.C:
+			lobby.logTelemetry()
+		case <-lobby.clockSync:
+			lobby.onClockSync()
+		case <-lobby.gameOverDelay:
+			lobby.sendPendingGameOver()
+		case <-lobby.challengeTimeout:
+			lobby.onChallengeTimeout()
 		}
 	}
 }
 
-func (lobby *Lobby) BroadcastShutdown() {
-	lobby.BroadcastMessage(Message{Type: Shutdown})
+// BroadcastShutdown lets the clients know the lobby is about to close, and why
+func (lobby *Lobby) BroadcastShutdown(reason shutdownReason) {
+	lobby.BroadcastMessage(Message{Type: Shutdown, Content: ShutdownContent{Reason: reason}})
 }
 
 func (lobby *Lobby) onClientJoin(joiningClient *Client) {
-	lobby.logger.Printf("%s connected", joiningClient)
+	lobby.logger.Info("client connected", "client", joiningClient.String())
+
+	if lobby.settings.GameMode == HotSeatGameMode {
+		lobby.joinHotSeatDevice(joiningClient)
+		return
+	}
+
+	if reconnectingClient, ok := lobby.reconnecting[joiningClient.id]; ok {
+		lobby.onClientRejoin(joiningClient, reconnectingClient)
+		return
+	}
+
+	// clients whose place is being held still count, since they can come back at any moment
+	if len(lobby.clients)+len(lobby.reconnecting) >= lobby.settings.MaxPlayers {
+		lobby.logger.Info("turning away client because the lobby is full", "client", joiningClient.String())
+		joiningClient.write <- Message{Type: LobbyFull, Content: LobbyFullContent{MaxPlayers: lobby.settings.MaxPlayers}} // see Client.Write
+		return
+	}
 
 	if lobby.status != InProgress {
 		lobby.aliveClients = append(lobby.aliveClients, joiningClient)
+	} else {
+		joiningClient.spectator = true
 	}
+	lobby.assignTeam(joiningClient)
+
+	lobby.claimHostIfVacant(joiningClient)
 
 	// fill in the client on everything they missed
-	joiningClient.write <- Message{Type: ClientDetails, Content: lobby.BuildClientDetails(joiningClient.id)}
+	clientDetails := lobby.BuildClientDetails(joiningClient.id)
+	clientDetails.RejoinToken = joiningClient.rejoinToken
+	joiningClient.write <- Message{Type: ClientDetails, Content: clientDetails}
 
 	// then add them to the lobby and broadcast that they joined to everyone (including to the new client)
 	lobby.clients[joiningClient.id] = joiningClient
+	lobby.updateClientCount()
 	lobby.BroadcastMessage(Message{Type: ClientJoined, Content: ClientJoinedContent{
 		ClientId:    joiningClient.id,
 		DisplayName: joiningClient.displayName,
 		IconName:    joiningClient.iconName,
-		// for new clients, they are considered alive if they join mid-game or after the game
-		Alive: lobby.status != InProgress,
+		// for new clients, they are considered alive if they join before or after the game
+		// clients joining mid-game can only watch it
+		Alive:       lobby.status != InProgress,
+		IsSpectator: joiningClient.spectator,
+		HostId:      lobby.hostId,
 	}})
+	lobby.startFinalIfReady()
 }
 
 func (lobby *Lobby) onClientLeave(leavingClient *Client) {
 	// clients are really two goroutines (for reading and writing) which will both announce their exit to the server
 	// so, need to prevent firing duplicate messages when they leave
-	if _, exists := lobby.clients[leavingClient.id]; !exists {
+	// (the id is compared by client too, since a client which has rejoined takes the id of the one it replaced)
+	if lobby.clients[leavingClient.id] != leavingClient {
 		return
 	}
 
-	lobby.logger.Printf("%s disconnected", leavingClient)
+	lobby.logger.Info("client disconnected", "client", leavingClient.String())
 
 	delete(lobby.clients, leavingClient.id)
-	lobby.BroadcastMessage(Message{Type: ClientLeft, Content: leavingClient.id})
+	delete(lobby.chatTokens, leavingClient.id)
+	delete(lobby.lastSyncRequest, leavingClient.id)
+	delete(lobby.lastSuggestionTurn, leavingClient.id)
+	lobby.updateClientCount()
+
+	if !lobby.holdForReconnect(leavingClient) {
+		lobby.removeClient(leavingClient)
+	}
+	if lobby.isHost(leavingClient.id) {
+		lobby.promoteNextHost()
+	}
+}
+
+// removeClient lets everyone know the (already disconnected) client has left the lobby, and updates the game accordingly
+func (lobby *Lobby) removeClient(leavingClient *Client) {
+	reason := DisconnectedLeaveReason
+	if leavingClient.seatOf != nil {
+		reason = DeviceDisconnectedLeaveReason
+	} else if lobby.IsKicked(leavingClient.rejoinToken) {
+		reason = KickedLeaveReason
+	}
+	lobby.BroadcastMessage(Message{Type: ClientLeft, Content: ClientLeftContent{ClientId: leavingClient.id, Reason: reason}})
+
+	if leavingClient == lobby.hotSeatDevice {
+		lobby.leaveHotSeatDevice(leavingClient)
+	}
 
 	// the rest of the code in here is concerned with leaving aliveClients in a consistent state
 	// if the game isn't currently in progress or the leaving client is already eliminated, then there is nothing left to do
@@ -157,9 +448,26 @@ func (lobby *Lobby) onClientLeave(leavingClient *Client) {
 	}
 
 	// handle game end based on leaving
+	if lobby.isTeamMode() && !lobby.hasTeammate(leavingClient) {
+		// the last of their team left, so the other team wins
+		lobby.setStatus(Over)
+		lobby.logger.Info("last client on a team left",
+			"status", lobby.status, "client", leavingClient.String(), "team", teamName(leavingClient.teamId))
+		lobby.declareTeamWinner(otherTeamId(leavingClient.teamId), OpponentsLeftWin)
+		return
+	}
+
+	if len(lobby.aliveClients) == 1 {
+		// the only client in a solo game left, so there is nobody left to win
+		lobby.setStatus(Over)
+		lobby.aliveClients = nil
+		lobby.logger.Info("client left a solo game", "status", lobby.status, "client", leavingClient.String())
+		return
+	}
+
 	if len(lobby.aliveClients) == 2 {
 		// only one client alive, we have a winner
-		lobby.status = Over
+		lobby.setStatus(Over)
 
 		// we're here because there are 2 clients remaining and one of them just left
 		// so, the winner is the *other* one
@@ -170,21 +478,22 @@ func (lobby *Lobby) onClientLeave(leavingClient *Client) {
 			winningClient = lobby.aliveClients[0]
 		}
 
-		lobby.winnersName = winningClient.displayName
-		lobby.logger.Printf("Set the status to %s because %s left, which makes %s the winner", lobby.status, leavingClient, winningClient)
-		lobby.BroadcastMessage(Message{Type: GameOver, Content: winningClient.id})
+		lobby.logger.Info("client left, leaving one winner",
+			"status", lobby.status, "client", leavingClient.String(), "winner", winningClient.String())
+		lobby.declareWinner(winningClient, OpponentsLeftWin)
 		return
 	}
 
 	// if a client leaves during their turn, remove them from the aliveClients list, and change the turn to the next client
 	leavingClientTurnIndex := slices.Index(lobby.aliveClients, leavingClient)
 	if leavingClientTurnIndex == lobby.turnIndex {
-		lobby.logger.Printf("Changing the current turn because %s left while it was their turn", leavingClient)
+		lobby.logger.Info("changing the turn because the client whose turn it was left", "client", leavingClient.String())
 		lobby.changeTurn(true)
 		return
 	}
 
 	// if it's not their turn, no need to change the turn. can go ahead and remove them from aliveClients
+	markEliminated(leavingClient)
 	aliveClients := make([]*Client, 0, len(lobby.aliveClients)-1)
 	for _, c := range lobby.aliveClients {
 		if c.id != leavingClient.id {
@@ -197,44 +506,88 @@ func (lobby *Lobby) onClientLeave(leavingClient *Client) {
 	if leavingClientTurnIndex < lobby.turnIndex {
 		lobby.turnIndex--
 	}
+
+	lobby.broadcastAliveClients()
+}
+
+// broadcastAliveClients sends every client the ids of the clients who are still alive, in turn order
+// this is sent whenever a client is removed from aliveClients, so the clients never need to work it out themselves
+func (lobby *Lobby) broadcastAliveClients() {
+	aliveClientIds := make([]int, 0, len(lobby.aliveClients))
+	for _, c := range lobby.aliveClients {
+		aliveClientIds = append(aliveClientIds, c.id)
+	}
+	lobby.BroadcastMessage(Message{Type: AliveClientsUpdated, Content: AliveClientsUpdatedContent{AliveClientIds: aliveClientIds}})
 }
 
 func (lobby *Lobby) onMessage(message Message) {
-	switch message.Type {
-	case StartGame:
-		lobby.onStartGame(message)
-	case RestartGame:
-		lobby.onRestartGame(message)
-	case AnswerPreview:
-		lobby.onAnswerPreview(message)
-	case SubmitAnswer:
-		lobby.onAnswerSubmitted(message)
-	case NameChange:
-		lobby.onNameChange(message)
-	default:
-		lobby.logger.Printf("Received message with type %s. Ignoring due to no handler function", message.Type)
+	// clients which were turned away (or kicked) can still send a few messages before their connection closes
+	if _, ok := lobby.clients[message.From]; !ok {
+		lobby.logger.Info("ignoring message from a client who is not in the lobby", "type", message.Type, "clientId", message.From)
+		return
+	}
+
+	message = lobby.routeHotSeatMessage(message)
+	if handler, ok := lobby.handlers[message.Type]; ok {
+		handler(message)
+	} else {
+		lobby.logger.Warn("ignoring message with no handler function", "type", message.Type)
+		lobby.telemetry.droppedMessages++
 	}
 }
 
 func (lobby *Lobby) onTurnExpired() {
 	// sometimes, depending on timing, our timer can fire after the players have left
 	if lobby.status != InProgress {
-		lobby.logger.Printf("Ignoring %s message because lobby is in %s status", TurnExpired, lobby.status)
+		lobby.logger.Info("ignoring message because the game is not in progress", "type", TurnExpired, "status", lobby.status)
 		return
 	}
+	lobby.observeTurnDuration()
 
 	eliminatedClient := lobby.aliveClients[lobby.turnIndex]
+	if lobby.isReconnecting(eliminatedClient) {
+		// every alive client is reconnecting. nobody is eliminated while their place is being held, so just move the turn along
+		lobby.changeTurn(false)
+		return
+	}
+
+	if lobby.isProtectedTurn() {
+		// it's too early in the game for anyone to go out, so running out of time only costs them points
+		lobby.logger.Info("client ran out of time during a protected turn", "client", eliminatedClient.String(), "turnCount", lobby.turnCount)
+		lobby.penalizeExpiredTurn(eliminatedClient)
+		lobby.changeTurn(false)
+		return
+	}
+
+	if lobby.isTeamMode() {
+		lobby.onTeamTurnExpired(eliminatedClient)
+		return
+	}
+
+	if lobby.loseLife(eliminatedClient) {
+		lobby.logger.Info("client ran out of time", "client", eliminatedClient.String(), "livesLeft", lobby.lives[eliminatedClient.id])
+		lobby.penalizeExpiredTurn(eliminatedClient)
+		lobby.changeTurn(false)
+		return
+	}
+
 	lobby.BroadcastMessage(Message{Type: TurnExpired, Content: TurnExpiredContent{
-		EliminatedClientId: eliminatedClient.id,
-		Suggestions:        words.GetChallengeSuggestions(lobby.currentChallenge),
+		ClientId:    eliminatedClient.id,
+		Suggestions: lobby.wordList().GetChallengeSuggestions(lobby.currentChallenge),
 	}})
+	lobby.penalizeExpiredTurn(eliminatedClient)
 
 	if len(lobby.aliveClients) > 2 {
 		// at least 2 clients still alive still, keep the game going (lobby#changeTurn will handle dropping them)
 		lobby.changeTurn(true)
+	} else if len(lobby.aliveClients) == 1 {
+		// the only client in a solo game is out. there's nobody for them to have beaten, so nobody wins
+		lobby.setStatus(Over)
+		lobby.logger.Info("client ran out of time, ending their solo game", "status", lobby.status, "client", eliminatedClient.String())
+		lobby.endSoloGame(eliminatedClient)
 	} else {
 		// only one client alive, we have a winner
-		lobby.status = Over
+		lobby.setStatus(Over)
 
 		// we're here because there are 2 clients remaining and one of them just had their turn expire
 		// so, the winner is the *other* one
@@ -246,113 +599,401 @@ func (lobby *Lobby) onTurnExpired() {
 			winningClient = lobby.aliveClients[0]
 		}
 
-		lobby.aliveClients = []*Client{winningClient}
-		lobby.winnersName = winningClient.displayName
+		lobby.logger.Info("client ran out of time, leaving one winner",
+			"status", lobby.status, "client", eliminatedClient.String(), "winner", winningClient.String())
+		lobby.declareWinner(winningClient, TurnExpiredWin)
+	}
+}
+
+// declareWinner wraps up a game which winningClient has just won, letting everyone (including any tournament) know
+func (lobby *Lobby) declareWinner(winningClient *Client, reason winReason) {
+	for _, c := range lobby.aliveClients {
+		if c != winningClient {
+			markEliminated(c)
+		}
+	}
+	lobby.aliveClients = []*Client{winningClient}
+	lobby.broadcastAliveClients()
+	lobby.winnersName = winningClient.displayName
+	if lobby.isTeamMode() {
+		lobby.winnersName = teamName(winningClient.teamId)
+	}
+	gameOver := lobby.buildGameOverContent(winningClient, reason)
+	lobby.announceGameOver(gameOver)
+	lobby.reportDailyResults(gameOver)
+	lobby.recordGameResult(winningClient)
+	lobby.reportTournamentResult(winningClient)
+	lobby.advanceWinnerToFinals(winningClient)
+}
 
-		lobby.logger.Printf("Set the status to %s because %s ran out of time, which makes %s the winner",
-			lobby.status, eliminatedClient, winningClient)
+// endSoloGame wraps up a solo game once soloClient, its only client, is out
+// nobody wins a solo game, so everyone is only told soloClient's score
+func (lobby *Lobby) endSoloGame(soloClient *Client) {
+	markEliminated(soloClient)
+	lobby.aliveClients = nil
+	lobby.broadcastAliveClients()
+	lobby.winnersName = ""
+	gameOver := lobby.buildGameOverContent(nil, SoloGameOverWin)
+	lobby.announceGameOver(gameOver)
+	lobby.reportDailyResults(gameOver)
+	lobby.recordGameResult(nil)
+}
 
-		lobby.BroadcastMessage(Message{Type: GameOver, Content: winningClient.id})
+// onGracePeriodEnded fires a little after each turn starts, once the client has had a chance to read the challenge
+func (lobby *Lobby) onGracePeriodEnded() {
+	if lobby.status != InProgress {
+		return
 	}
+
+	lobby.BroadcastMessage(Message{Type: GracePeriodEnd})
 }
 
 func (lobby *Lobby) onStartGame(message Message) {
-	if lobby.status == WaitingForPlayers && len(lobby.clients) >= 2 {
-		lobby.logger.Printf("%s has started the game", lobby.clients[message.From])
-		lobby.status = InProgress
-		lobby.changeTurn(false)
+	if !lobby.isHost(message.From) {
+		lobby.denyPermission(message)
+		return
+	}
+
+	if lobby.status == WaitingForPlayers && len(lobby.clients) < lobby.settings.MinPlayers {
+		lobby.sendNotEnoughPlayers(message.From, len(lobby.clients))
+		return
+	}
+
+	if lobby.status == WaitingForPlayers {
+		lobby.logger.Info("game started", "client", lobby.clients[message.From].String())
+		lobby.startGame()
 	}
 }
 
+// startGame starts the lobby's first game with the clients who have joined so far
+func (lobby *Lobby) startGame() {
+	lobby.setStatus(InProgress)
+	lobby.usedAnswers = make(map[string]struct{})
+	lobby.resetScores()
+	lobby.resetLives()
+	lobby.resetTeams()
+	lobby.changeTurn(false)
+}
+
 func (lobby *Lobby) onRestartGame(message Message) {
-	if lobby.status == Over && len(lobby.clients) >= 2 {
-		lobby.logger.Printf("%s has restarted the game", lobby.clients[message.From])
+	if !lobby.isHost(message.From) {
+		lobby.denyPermission(message)
+		return
+	}
+
+	if lobby.status == Over && lobby.countNextGamePlayers() < lobby.settings.MinPlayers {
+		lobby.sendNotEnoughPlayers(message.From, lobby.countNextGamePlayers())
+		return
+	}
+
+	if lobby.status == Over {
+		lobby.logger.Info("game restarted", "client", lobby.clients[message.From].String())
+		lobby.sendPendingGameOver()
 		lobby.resetAliveClients()
-		lobby.status = InProgress
+		lobby.setStatus(InProgress)
 		lobby.turnIndex = -1
 		lobby.turnRounds = 0
+		lobby.turnCount = 0
+		lobby.difficultyCapped = false
+		lobby.vaultIndex = 0
+		lobby.acceptedAnswers = nil
+		lobby.usedAnswers = make(map[string]struct{})
+		lobby.resetChallengeStats()
+		lobby.resetScores()
+		lobby.resetTeams()
 		lobby.BroadcastMessage(Message{Type: RestartGame})
 		lobby.changeTurn(false)
 	}
 }
 
 func (lobby *Lobby) resetAliveClients() {
+	// spectators who asked to play (see onPromoteObserver) do so from this game on. the rest carry on watching
+	for _, c := range lobby.pendingPromotion {
+		c.spectator = false
+	}
+	lobby.pendingPromotion = nil
+
+	// every client can use any answer again in the new game
+	clear(lobby.clientAnswerHistory)
+
 	// reset alive clients to hold all clients
 	lobby.aliveClients = slices.SortedFunc(maps.Values(lobby.clients), func(c1 *Client, c2 *Client) int {
 		return c1.id - c2.id
 	})
+	lobby.aliveClients = slices.DeleteFunc(lobby.aliveClients, func(c *Client) bool {
+		return !lobby.isPlayer(c) || c.spectator
+	})
+	lobby.resetLives()
+}
+
+// sendNotEnoughPlayers tells the client who tried to (re)start the game that it needs more players first
+func (lobby *Lobby) sendNotEnoughPlayers(clientId int, players int) {
+	lobby.logger.Info("not enough players to start the game",
+		"client", lobby.clients[clientId].String(), "players", players, "minPlayers", lobby.settings.MinPlayers)
+	lobby.SendToClient(clientId, Message{Type: NotEnoughPlayers, Content: NotEnoughPlayersContent{
+		Players:    players,
+		MinPlayers: lobby.settings.MinPlayers,
+	}})
+}
+
+// countNextGamePlayers returns how many clients would play if the game was restarted now
+func (lobby *Lobby) countNextGamePlayers() int {
+	count := 0
+	for _, c := range lobby.clients {
+		if lobby.isPlayer(c) && (!c.spectator || slices.Contains(lobby.pendingPromotion, c)) {
+			count++
+		}
+	}
+	return count
+}
+
+// onPromoteObserver lets a spectator play in the next game, once the game they were watching is over
+func (lobby *Lobby) onPromoteObserver(message Message) {
+	client := lobby.clients[message.From]
+	if lobby.status != Over || !client.spectator || slices.Contains(lobby.pendingPromotion, client) {
+		return
+	}
+
+	lobby.logger.Info("observer will play in the next game", "client", client.String())
+	lobby.pendingPromotion = append(lobby.pendingPromotion, client)
+	lobby.BroadcastMessage(Message{Type: PromoteObserver, Content: PromoteObserverContent{ClientId: client.id}})
 }
 
 func (lobby *Lobby) onNameChange(message Message) {
-	newDisplayName, ok := message.Content.(string)
-	if !ok || len(newDisplayName) > MaxDisplayName {
+	newDisplayName, err := DecodeContent[string](message)
+	if err != nil || len(newDisplayName) > MaxDisplayName {
 		return
 	}
 
 	client := lobby.clients[message.From]
-	client.displayName = newDisplayName
+	client.setDisplayName(newDisplayName)
 	lobby.BroadcastMessage(Message{Type: NameChange, Content: ClientNameChange{ClientId: client.id, NewDisplayName: newDisplayName}})
 }
 
+// onNegotiate switches the format used for all messages sent to the client from now on, e.g. to MessagePack
+func (lobby *Lobby) onNegotiate(message Message) {
+	formatName, err := DecodeContent[string](message)
+	if err != nil {
+		return
+	}
+
+	format, ok := negotiableFormats[formatName]
+	if !ok {
+		lobby.logger.Info("client asked for unknown message format", "client", lobby.clients[message.From].String(), "format", formatName)
+		return
+	}
+
+	lobby.clients[message.From].format.Store(int32(format))
+}
+
 func (lobby *Lobby) onAnswerPreview(message Message) {
 	if lobby.status == InProgress && message.From == lobby.aliveClients[lobby.turnIndex].id {
-		currentAnswerPrev, ok := message.Content.(string)
-		if ok {
+		currentAnswerPrev, err := DecodeContent[string](message)
+		if err == nil {
 			lobby.currentAnswerPrev = currentAnswerPrev
-			lobby.BroadcastMessage(Message{Type: AnswerPreview, Content: lobby.currentAnswerPrev})
+			lobby.BroadcastMessage(Message{Type: AnswerPreview, Content: AnswerPreviewContent{
+				ClientId: message.From,
+				Preview:  lobby.currentAnswerPrev,
+			}})
 		}
 	}
 }
 
 func (lobby *Lobby) onAnswerSubmitted(message Message) {
-	if lobby.status == InProgress && message.From == lobby.aliveClients[lobby.turnIndex].id {
-		answer, ok := message.Content.(string)
-		if !ok {
+	if lobby.status == InProgress && !lobby.awaitingChallenge && message.From == lobby.aliveClients[lobby.turnIndex].id {
+		answer, err := DecodeContent[string](message)
+		if err != nil {
 			return
 		}
 
-		if !words.IsValidWord(answer) {
-			lobby.logger.Printf("%s submitted '%s' for challenge '%s' - rejected because it's not a word",
-				lobby.aliveClients[lobby.turnIndex], answer, lobby.currentChallenge)
-			lobby.BroadcastMessage(Message{Type: AnswerRejected, Content: answer})
+		// answers sent too soon after the last one are turned away before any checks, without counting against the client
+		minInterval := time.Duration(lobby.settings.MinAnswerIntervalMs) * time.Millisecond
+		if lastSubmit, ok := lobby.lastSubmitTime[message.From]; ok && time.Since(lastSubmit) < minInterval {
+			lobby.SendToClient(message.From, Message{Type: AnswerRejected, Content: AnswerRejectedContent{
+				Answer:   answer,
+				Reason:   TooFastRejection,
+				ClientId: message.From,
+			}})
 			return
 		}
+		if lobby.lastSubmitTime == nil {
+			lobby.lastSubmitTime = make(map[int]time.Time)
+		}
+		lobby.lastSubmitTime[message.From] = time.Now()
 
-		if answer == lobby.currentChallenge {
-			lobby.logger.Printf("%s submitted %s for challenge %s - rejected because it's the same as the challenge",
-				lobby.aliveClients[lobby.turnIndex], answer, lobby.currentChallenge)
-			lobby.BroadcastMessage(Message{Type: AnswerRejected, Content: answer})
+		challengeText := lobby.getChallengeText()
+		if answer == challengeText {
+			lobby.logger.Info("answer rejected",
+				"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", SameAsChallengeRejection)
+			lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, SameAsChallengeRejection)
 			return
 		}
 
-		if !strings.Contains(answer, lobby.currentChallenge) {
-			lobby.logger.Printf("%s submitted %s for challenge %s - rejected because it does not contain the challenge",
-				lobby.aliveClients[lobby.turnIndex], answer, lobby.currentChallenge)
-			lobby.BroadcastMessage(Message{Type: AnswerRejected, Content: answer})
+		if !strings.Contains(answer, challengeText) {
+			lobby.logger.Info("answer rejected",
+				"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", MissingChallengeRejection)
+			lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, MissingChallengeRejection)
 			return
 		}
 
-		lobby.logger.Printf("%s submitted %s for challenge %s - accepted", lobby.aliveClients[lobby.turnIndex], answer, lobby.currentChallenge)
-		lobby.BroadcastMessage(Message{Type: AnswerAccepted, Content: answer})
-		lobby.changeTurn(false)
+		if position := lobby.settings.ChallengePosition; position >= 0 && !strings.HasPrefix(answer[min(position, len(answer)):], challengeText) {
+			lobby.logger.Info("answer rejected",
+				"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", WrongPositionRejection, "position", position)
+			lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, WrongPositionRejection)
+			return
+		}
+
+		// the validation workers are shared by every lobby, so each client only gets one answer validated at a time
+		if pendingAnswer, pending := lobby.validationPending[message.From]; pending {
+			lobby.logger.Info("answer ignored because another is still being validated",
+				"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "pendingAnswer", pendingAnswer)
+			lobby.telemetry.droppedMessages++
+			return
+		}
+
+		// the remaining checks are finished in onAnswerValidated, once the answer has been checked against the word list
+		lobby.validateAnswer(answer, message.From)
+	}
+}
+
+// onAnswerValidated finishes checking an answer submitted in onAnswerSubmitted, once it's been checked against the word list
+func (lobby *Lobby) onAnswerValidated(result validationResult) {
+	delete(lobby.validationPending, result.clientId)
+
+	// the turn may have ended while the answer was being validated, in which case the answer no longer matters
+	if lobby.status != InProgress || result.turnCount != lobby.turnCount || result.clientId != lobby.aliveClients[lobby.turnIndex].id {
+		lobby.telemetry.droppedMessages++
+		return
+	}
+
+	answer := result.answer
+	if !result.valid {
+		lobby.logger.Info("answer rejected",
+			"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", NotAWordRejection)
+		lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, NotAWordRejection)
+		return
+	}
+
+	if lobby.isBannedAnswer(answer) {
+		lobby.logger.Info("answer rejected",
+			"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", BannedAnswerRejection)
+		lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, BannedAnswerRejection)
+		return
+	}
+
+	if _, used := lobby.clientAnswerHistory[result.clientId][answer]; used && lobby.settings.NoRepeat {
+		lobby.logger.Info("answer rejected",
+			"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", AlreadyUsedByYouRejection)
+		lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, AlreadyUsedByYouRejection)
+		return
+	}
+
+	if _, used := lobby.usedAnswers[answer]; used && !lobby.settings.AllowReuse {
+		lobby.logger.Info("answer rejected",
+			"client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge, "reason", AlreadyUsedRejection)
+		lobby.rejectAnswer(lobby.aliveClients[lobby.turnIndex], answer, AlreadyUsedRejection)
+		return
+	}
+
+	lobby.logger.Info("answer accepted", "client", lobby.aliveClients[lobby.turnIndex].String(), "answer", answer, "challenge", lobby.currentChallenge)
+	previousTimeLimit := lobby.getTurnLimitDuration()
+	lobby.recordAnswerOutcome(true)
+	lobby.acceptedAnswers = append(lobby.acceptedAnswers, answer)
+	lobby.telemetry.acceptedAnswers++
+	metrics.AnswersAccepted.Inc()
+	lobby.observeTurnDuration()
+	lobby.usedAnswers[answer] = struct{}{}
+	lobby.addToAnswerHistory(result.clientId, answer)
+	lobby.BroadcastMessage(Message{Type: AnswerAccepted, Content: AnswerAcceptedContent{
+		ClientId: lobby.aliveClients[lobby.turnIndex].id,
+		Answer:   answer,
+	}})
+	lobby.awardPoints(lobby.aliveClients[lobby.turnIndex], answer)
+	lobby.extendStreak(lobby.aliveClients[lobby.turnIndex])
+	lobby.rewardTeamAnswer(lobby.aliveClients[lobby.turnIndex])
+	lobby.restoreLife(lobby.aliveClients[lobby.turnIndex])
+	lobby.checkTimeLimitChange(previousTimeLimit)
+	if lobby.reachedPointsToWin(lobby.aliveClients[lobby.turnIndex]) {
+		// nobody else can have reached PointsToWin yet, so this client has the highest score
+		lobby.declareScoreWinner(PointsToWinWin)
+		return
+	}
+	lobby.changeTurnAfterAnswer()
+}
+
+// addToAnswerHistory records that the client has had answer accepted, so they can't use it again this game if the lobby has NoRepeat enabled
+func (lobby *Lobby) addToAnswerHistory(clientId int, answer string) {
+	if lobby.clientAnswerHistory == nil {
+		lobby.clientAnswerHistory = make(map[int]map[string]struct{})
+	}
+	history, ok := lobby.clientAnswerHistory[clientId]
+	if !ok {
+		history = make(map[string]struct{})
+		lobby.clientAnswerHistory[clientId] = history
+	}
+	history[answer] = struct{}{}
+}
+
+// isBannedAnswer returns whether the answer is one of LobbySettings.BannedAnswers, ignoring case
+func (lobby *Lobby) isBannedAnswer(answer string) bool {
+	return slices.ContainsFunc(lobby.settings.BannedAnswers, func(bannedAnswer string) bool {
+		return strings.EqualFold(bannedAnswer, answer)
+	})
+}
+
+// rejectAnswer lets the clients know that submittingClient's answer was not accepted, and why
+// if the lobby hides rejections, only submittingClient is told, so opponents can't learn what they've tried
+func (lobby *Lobby) rejectAnswer(submittingClient *Client, answer string, reason rejectionReason) {
+	message := Message{Type: AnswerRejected, Content: AnswerRejectedContent{
+		Answer:   answer,
+		Reason:   reason,
+		ClientId: submittingClient.id,
+	}}
+	lobby.telemetry.rejectedAnswers++
+	metrics.AnswersRejected.WithLabelValues(string(reason)).Inc()
+	lobby.recordAnswerOutcome(false)
+
+	if lobby.settings.HideRejections {
+		lobby.SendToClient(submittingClient.id, message)
+	} else {
+		lobby.BroadcastMessage(message)
+	}
+	lobby.loseDoubleDown(submittingClient)
+	lobby.breakStreak(submittingClient)
+}
+
+// changeTurn moves the turn on to the next client and starts it with a newly generated challenge
+// see passTurn for what removeCurrentClient means
+func (lobby *Lobby) changeTurn(removeCurrentClient bool) {
+	if lobby.passTurn(removeCurrentClient) {
+		lobby.generateChallenge()
+		lobby.startTurn()
 	}
 }
 
 // removeCurrentClient indicates if the client (whose turn it is) has gone out
 // this can happen either by time running out, or by the client disconnecting
 // regardless, it is the responsibility of this method to properly update the aliveClients and turnIndex variables
-func (lobby *Lobby) changeTurn(removeCurrentClient bool) {
+// the next turn is left for the caller to start, unless passTurn returns false because the game ended instead
+func (lobby *Lobby) passTurn(removeCurrentClient bool) bool {
+	// a turn which was still waiting on the host's challenge is skipped along with everything else about it
+	lobby.stopAwaitingChallenge()
+	previousDifficulty := lobby.getTurnDifficulty()
+	wasMultiplierTurn := lobby.isMultiplierTurn()
+
 	if !removeCurrentClient {
 		// if the last client didn't run out of time or disconnect, this is easy
-		newTurnIndex := (lobby.turnIndex + 1) % len(lobby.aliveClients)
+		// clients who are reconnecting are skipped over, since they aren't there to answer
+		newTurnIndex := lobby.nextConnectedIndex((lobby.turnIndex + 1) % len(lobby.aliveClients))
 		if lobby.turnIndex > -1 {
-			lobby.logger.Printf("Changing turn from %s to %s", lobby.aliveClients[lobby.turnIndex], lobby.aliveClients[newTurnIndex])
+			lobby.logger.Info("changing turn", "from", lobby.aliveClients[lobby.turnIndex].String(), "to", lobby.aliveClients[newTurnIndex].String())
 		} else {
-			lobby.logger.Printf("Starting turn with %s", lobby.aliveClients[newTurnIndex])
+			lobby.logger.Info("starting turn", "client", lobby.aliveClients[newTurnIndex].String())
 		}
 		lobby.turnIndex = newTurnIndex
 	} else {
 		eliminatedClient := lobby.aliveClients[lobby.turnIndex]
+		markEliminated(eliminatedClient)
 		// if they ran out of time or disconnected:
 		// - kick them out of the aliveClients
 		// - turnIndex can stay the same (since the next client will now occupy that index)
@@ -368,33 +1009,187 @@ func (lobby *Lobby) changeTurn(removeCurrentClient bool) {
 		if lobby.turnIndex == len(lobby.aliveClients) {
 			lobby.turnIndex = 0
 		}
+		lobby.turnIndex = lobby.nextConnectedIndex(lobby.turnIndex)
+
+		lobby.logger.Info("changing turn from an eliminated client", "from", eliminatedClient.String(), "to", lobby.aliveClients[lobby.turnIndex].String())
+		lobby.broadcastAliveClients()
+	}
 
-		lobby.logger.Printf("Changing turn from %s (eliminated) to %s", eliminatedClient, lobby.aliveClients[lobby.turnIndex])
+	if lobby.reachedMaxTurns() {
+		lobby.declareScoreWinner(MaxTurnsWin)
+		return false
 	}
 
+	lobby.turnCount++
+	if wasMultiplierTurn && !lobby.isMultiplierTurn() {
+		lobby.BroadcastMessage(Message{Type: MultiplierRoundInactive})
+	}
 	if lobby.turnIndex == 0 {
 		lobby.turnRounds++
+		lobby.BroadcastMessage(Message{Type: RoundStarted, Content: RoundStartedContent{
+			RoundNumber:     lobby.turnRounds,
+			DifficultyLabel: lobby.getTurnDifficulty().String(),
+		}})
 	}
+	lobby.telemetry.totalTurns++
+	lobby.doubleDownActive = false
+	clear(lobby.lastSubmitTime)
+	lobby.checkDifficultyChange(previousDifficulty)
+	return true
+}
 
+// generateChallenge picks the challenge for the current turn, from the vault if there's any left or at random otherwise
+func (lobby *Lobby) generateChallenge() {
+	lobby.currentChallenge = lobby.takeVaultChallenge()
+	if lobby.currentChallenge == "" {
+		lobby.currentChallenge = lobby.getNextChallenge(lobby.getTurnDifficulty())
+	}
+	if lobby.currentChallenge == "" {
+		lobby.capDifficulty()
+	}
+}
+
+// observeTurnDuration records how long the current turn lasted, once it's over
+func (lobby *Lobby) observeTurnDuration() {
+	turnStart := lobby.turnDeadline.Add(-lobby.turnLimit)
+	metrics.TurnDuration.Observe(time.Since(turnStart).Seconds())
+}
+
+// startTurn starts the clock on the current turn, and lets everyone know whose turn it is and what their challenge is
+func (lobby *Lobby) startTurn() {
 	turnLimitDuration := lobby.getTurnLimitDuration()
-	lobby.currentTurnEnd = time.Now().Add(turnLimitDuration).UnixMilli()
-	lobby.turnExpired = time.After(turnLimitDuration)
-	lobby.currentChallenge = words.GetChallenge(lobby.getTurnDifficulty())
-
-	lobby.BroadcastMessage(Message{
-		Type: ClientsTurn,
-		Content: ClientsTurnContent{
-			ClientId:  lobby.aliveClients[lobby.turnIndex].id,
-			Challenge: lobby.currentChallenge,
-			TurnEnd:   lobby.currentTurnEnd,
-		},
-	})
+	if lobby.settings.GameMode == HotSeatGameMode {
+		turnLimitDuration *= 2 // players need time to pass the device to each other
+	}
+	turnLimitDuration += lobby.getLatencyAllowance(lobby.aliveClients[lobby.turnIndex])
+	turnLimitDuration += lobby.takeTeamBonus(lobby.aliveClients[lobby.turnIndex])
+	// the timer runs off the monotonic deadline, while clients are given the wall clock equivalent to count down to
+	lobby.turnLimit = turnLimitDuration
+	lobby.turnDeadline = time.Now().Add(turnLimitDuration)
+	lobby.currentTurnEnd = lobby.turnDeadline.UnixMilli()
+	lobby.turnExpired = time.After(time.Until(lobby.turnDeadline))
+	lobby.resetClockSync()
+	if lobby.settings.GraceBeforeFirstPreviewMs > 0 {
+		lobby.gracePeriodEnded = time.After(time.Duration(lobby.settings.GraceBeforeFirstPreviewMs) * time.Millisecond)
+	} else {
+		lobby.gracePeriodEnded = nil
+	}
+
+	lobby.BroadcastMessage(Message{Type: ClientsTurn, Content: lobby.buildClientsTurnContent()})
+}
+
+// buildClientsTurnContent describes the current turn: whose it is, their challenge and when it ends
+func (lobby *Lobby) buildClientsTurnContent() ClientsTurnContent {
+	clientsTurnContent := ClientsTurnContent{
+		ClientId:    lobby.aliveClients[lobby.turnIndex].id,
+		Challenge:   lobby.currentChallenge,
+		TurnEnd:     lobby.currentTurnEnd,
+		Difficulty:  lobby.getTurnDifficulty().String(),
+		TurnLimitMs: lobby.turnLimit.Milliseconds(),
+		Scores:      lobby.buildScores(),
+		UsedAnswers: len(lobby.usedAnswers),
+		RoundNumber: lobby.turnRounds,
+		TeamId:      lobby.aliveClients[lobby.turnIndex].teamId,
+	}
+	if lobby.isPracticeTurn() {
+		clientsTurnContent.PracticeRoundActive = &PracticeRoundActive{RemainingPracticeRounds: lobby.remainingPracticeRounds()}
+	}
+	if lobby.isMultiplierTurn() {
+		clientsTurnContent.MultiplierRoundActive = &MultiplierRoundActive{Multiplier: multiplierRoundPoints}
+	}
+	if lobby.settings.EducationalMode {
+		clientsTurnContent.ValidAnswerCount = lobby.countValidAnswers()
+		clientsTurnContent.ChallengeOrigin, _ = words.GetChallengeOrigin(lobby.currentChallenge)
+	}
+	return clientsTurnContent
+}
+
+// onRequestCurrentState re-sends the current turn to a client who may have missed it, e.g. after a dropped message
+// this is much cheaper than re-sending everything in ClientDetails
+func (lobby *Lobby) onRequestCurrentState(message Message) {
+	if lobby.status != InProgress || lobby.awaitingChallenge {
+		return
+	}
+	lobby.SendToClient(message.From, Message{Type: CurrentState, Content: lobby.buildClientsTurnContent()})
+}
+
+// countValidAnswers returns how many answers would be accepted for the current challenge
+func (lobby *Lobby) countValidAnswers() int {
+	return lobby.wordList().CountValidAnswers(lobby.getChallengeText())
+}
+
+// checkDifficultyChange lets the clients know if the challenges have gotten harder since previousDifficulty
+func (lobby *Lobby) checkDifficultyChange(previousDifficulty words.ChallengeDifficulty) {
+	// emoji challenges don't get any harder as the game goes on
+	if lobby.settings.ChallengeMode == EmojiChallengeMode {
+		return
+	}
+
+	newDifficulty := lobby.getTurnDifficulty()
+	if newDifficulty > previousDifficulty {
+		lobby.logger.Info("difficulty increased", "from", previousDifficulty.String(), "to", newDifficulty.String(), "rounds", lobby.turnRounds)
+		lobby.BroadcastMessage(Message{Type: DifficultyIncreased, Content: DifficultyIncreasedContent{NewDifficulty: newDifficulty.String()}})
+	}
+}
+
+// capDifficulty is called when there are no challenges for the turn's difficulty
+// it falls back to easier difficulties until a challenge is found, and lets the clients know the first time it happens each game
+func (lobby *Lobby) capDifficulty() {
+	difficulty := lobby.getTurnDifficulty()
+	lobby.logger.Warn("no challenges in the word list for the difficulty, falling back to an easier one", "difficulty", difficulty.String())
+	reason := fmt.Sprintf("no_%s_challenges", strings.ToLower(difficulty.String()))
+
+	for lobby.currentChallenge == "" && difficulty > words.ChallengeEasy {
+		difficulty--
+		lobby.currentChallenge = lobby.getNextChallenge(difficulty)
+	}
+	if lobby.currentChallenge == "" {
+		lobby.logger.Warn("no challenges of any difficulty in the word list")
+	}
+
+	if !lobby.difficultyCapped {
+		lobby.difficultyCapped = true
+		lobby.BroadcastMessage(Message{Type: DifficultyCapped, Content: DifficultyCappedContent{Reason: reason}})
+	}
+}
+
+// getNextChallenge picks the challenge of the given difficulty for a new turn, based on the lobby's ChallengeMode
+func (lobby *Lobby) getNextChallenge(difficulty words.ChallengeDifficulty) string {
+	switch lobby.settings.ChallengeMode {
+	case EmojiChallengeMode:
+		return words.GetEmojiChallenge()
+	default:
+		if lobby.settings.DailyChallenge {
+			return lobby.getDailyChallenge(difficulty)
+		}
+		if lobby.settings.ChallengePosition >= 0 {
+			return lobby.wordList().GetChallengeAtPosition(lobby.settings.ChallengePosition, difficulty)
+		}
+		return lobby.wordList().GetChallenge(difficulty)
+	}
+}
+
+// getChallengeText returns the text that answers must contain to satisfy the current challenge
+// for most modes this is the challenge itself, but e.g. emoji challenges need to be translated to their word first
+func (lobby *Lobby) getChallengeText() string {
+	switch lobby.settings.ChallengeMode {
+	case EmojiChallengeMode:
+		word, ok := words.GetEmojiWord(lobby.currentChallenge)
+		if !ok {
+			lobby.logger.Warn("no word found for emoji challenge", "challenge", lobby.currentChallenge)
+		}
+		return word
+	default:
+		return lobby.currentChallenge
+	}
 }
 
 func (lobby *Lobby) getTurnDifficulty() words.ChallengeDifficulty {
-	if lobby.turnRounds > 10 {
+	if lobby.isPracticeTurn() {
+		return words.ChallengeEasy
+	} else if lobby.turnRounds > lobby.settings.EasyRounds+lobby.settings.MediumRounds {
 		return words.ChallengeHard
-	} else if lobby.turnRounds > 4 {
+	} else if lobby.turnRounds > lobby.settings.EasyRounds {
 		return words.ChallengeMedium
 	} else {
 		return words.ChallengeEasy
@@ -402,21 +1197,61 @@ func (lobby *Lobby) getTurnDifficulty() words.ChallengeDifficulty {
 }
 
 func (lobby *Lobby) getTurnLimitDuration() time.Duration {
+	if lobby.isPracticeTurn() {
+		return practiceTurnLimit
+	}
+	if lobby.settings.GameMode == TimeAttackGameMode {
+		return lobby.getTimeAttackLimit()
+	}
+
+	// with the default settings, turns last 25, 20, 18 and finally 16 seconds
+	initialLimit := time.Duration(lobby.settings.InitialTurnSeconds) * time.Second
+	minimumLimit := time.Duration(lobby.settings.MinimumTurnSeconds) * time.Second
 	switch true {
 	case lobby.turnRounds > 12:
-		return 16 * time.Second // rounds 13+: 16 seconds
+		return minimumLimit // rounds 13+: the minimum
 	case lobby.turnRounds > 5:
-		return 18 * time.Second // rounds 6-12: 18 seconds
+		return min(minimumLimit+2*time.Second, initialLimit) // rounds 6-12: 2 seconds more than the minimum
 	case lobby.turnRounds > 1:
-		return 20 * time.Second // rounds 2-5: 20 seconds
-	case lobby.turnRounds == 1:
-		return 25 * time.Second // round 1: 25 seconds (give them bonus time to get familiar with the game)
+		return min(minimumLimit+4*time.Second, initialLimit) // rounds 2-5: 4 seconds more than the minimum
 	default:
-		lobby.logger.Printf("WARN: No turnLimit duration specified for %d turnRounds. Falling back to 20 second default.", lobby.turnRounds)
-		return 20 * time.Second
+		// round 1: the initial limit (give them bonus time to get familiar with the game)
+		// this also covers round 0, i.e. before changeTurn has counted the first round
+		return initialLimit
 	}
 }
 
+// buildGameOverContent summarizes the game which winningClient just won
+func (lobby *Lobby) buildGameOverContent(winningClient *Client, reason winReason) GameOverContent {
+	content := GameOverContent{
+		WinnerName:            lobby.winnersName,
+		Reason:                reason,
+		LongestStreak:         lobby.longestStreakRecord,
+		LongestStreakClientId: lobby.longestStreakHolder,
+		Scores:                lobby.buildScores(),
+		FinalStandings:        lobby.buildFinalStandings(winningClient),
+		HardestChallenges:     lobby.hardestChallenges(),
+	}
+	if winningClient != nil {
+		content.WinnerId = winningClient.id
+	}
+	if lobby.isTeamMode() {
+		content.WinningTeamId = winningClient.teamId
+	}
+
+	// the rarest word is the one answering the fewest challenges, with ties going to the longer word
+	for _, answer := range lobby.acceptedAnswers {
+		frequency := words.WordFrequency(answer)
+		if content.RarestWord == "" || frequency < content.RarestWordFrequency ||
+			(frequency == content.RarestWordFrequency && len(answer) > len(content.RarestWord)) {
+			content.RarestWord = answer
+			content.RarestWordFrequency = frequency
+		}
+	}
+
+	return content
+}
+
 // BuildClientDetails is responsible for building and returning a ClientDetailsContent struct
 // which contains the current state of the lobby for a newly connected client, so they can get caught up
 func (lobby *Lobby) BuildClientDetails(joiningClientId int) ClientDetailsContent {
@@ -426,16 +1261,23 @@ func (lobby *Lobby) BuildClientDetails(joiningClientId int) ClientDetailsContent
 	}
 
 	// sorted slice of clients (ensures ordering of clients is consistent for all players)
-	clients := slices.SortedFunc(maps.Values(lobby.clients), func(c1, c2 *Client) int {
+	// clients who are reconnecting are still part of the game, so they're included too
+	clients := slices.Collect(maps.Values(lobby.clients))
+	for _, reconnectingClient := range lobby.reconnecting {
+		clients = append(clients, reconnectingClient.client)
+	}
+	slices.SortFunc(clients, func(c1, c2 *Client) int {
 		return c1.id - c2.id
 	})
-	clientContents := make([]ClientContent, 0, len(lobby.clients))
+	clientContents := make([]ClientContent, 0, len(clients))
 	for _, c := range clients {
 		clientContents = append(clientContents, ClientContent{
-			Id:          c.id,
-			DisplayName: c.displayName,
-			IconName:    c.iconName,
-			Alive:       isAliveMap[c],
+			Id:           c.id,
+			DisplayName:  c.displayName,
+			IconName:     c.iconName,
+			Alive:        isAliveMap[c],
+			Reconnecting: lobby.isReconnecting(c),
+			IsSpectator:  c.spectator,
 		})
 	}
 
@@ -447,23 +1289,93 @@ func (lobby *Lobby) BuildClientDetails(joiningClientId int) ClientDetailsContent
 	}
 
 	return ClientDetailsContent{
-		ClientId:          joiningClientId,
-		Status:            lobby.status,
-		Clients:           clientContents,
-		CurrentTurnId:     currentTurnId,
-		CurrentChallenge:  lobby.currentChallenge,
-		CurrentAnswerPrev: lobby.currentAnswerPrev,
-		TurnEnd:           lobby.currentTurnEnd,
-		WinnersName:       lobby.winnersName,
+		ClientId:            joiningClientId,
+		Status:              lobby.status,
+		Clients:             clientContents,
+		CurrentTurnId:       currentTurnId,
+		CurrentChallenge:    lobby.currentChallenge,
+		CurrentAnswerPrev:   lobby.currentAnswerPrev,
+		TurnEnd:             lobby.currentTurnEnd,
+		WinnersName:         lobby.winnersName,
+		MinPlayers:          lobby.settings.MinPlayers,
+		IsPasswordProtected: lobby.IsPasswordProtected(),
+		HostId:              lobby.hostId,
+		SeatIds:             lobby.getSeatIds(joiningClientId),
+		Lives:               maps.Clone(lobby.lives),
+		ChatHistory:         lobby.chatHistory.latest(chatHistorySent),
+		Announcements:       lobby.announcements.flatten(),
 	}
 }
 
 func (lobby *Lobby) BroadcastMessage(message Message) {
 	for _, c := range lobby.clients {
+		if c.seatOf != nil {
+			continue // seats share their device's connection, which already gets the message
+		}
 		c.write <- message
 	}
 }
 
+// OfferFork lets the alive clients know that forkLobbyId has been created with this lobby's settings, so they can choose to move to it
+// returns false if the lobby has already ended
+func (lobby *Lobby) OfferFork(forkLobbyId uuid.UUID) bool {
+	select {
+	case lobby.forks <- forkLobbyId:
+		return true
+	case <-lobby.Done():
+		return false
+	}
+}
+
+func (lobby *Lobby) onForkAvailable(forkLobbyId uuid.UUID) {
+	lobby.logger.Info("offering fork to alive clients", "forkLobbyId", forkLobbyId, "aliveCount", len(lobby.aliveClients))
+	for _, client := range lobby.aliveClients {
+		lobby.SendToClient(client.id, Message{Type: ForkAvailable, Content: ForkAvailableContent{NewLobbyId: forkLobbyId}})
+	}
+}
+
+// SendToClient sends the message to only the client with the given id, returning false if there is no such client in the lobby
+func (lobby *Lobby) SendToClient(clientId int, message Message) bool {
+	client, ok := lobby.clients[clientId]
+	if !ok {
+		return false
+	}
+	if client.seatOf != nil {
+		client = client.seatOf
+	}
+
+	client.write <- message
+	return true
+}
+
+// Done returns a channel which is closed once the lobby has ended
+func (lobby *Lobby) Done() <-chan struct{} {
+	return lobby.done
+}
+
 func (lobby *Lobby) EndLobby() {
+	lobby.active.Store(false)
+	close(lobby.done)
 	lobby.lobbyOver <- lobby.Id
+	if lobby.store != nil {
+		lobby.saveGameResults()
+	}
+}
+
+// safeDisplayName returns the name to use for a client in log output
+// in streamer mode, players' real display names are kept out of the logs, so they are replaced with "Player <id>"
+func (lobby *Lobby) safeDisplayName(client *Client) string {
+	if lobby.settings.StreamerMode {
+		return fmt.Sprintf("Player %d", client.id)
+	}
+	return client.displayName
+}
+
+// debugContent formats a message's content for debug logging
+// in streamer mode the content is left out entirely, since messages can carry display names (and whatever players chat)
+func (lobby *Lobby) debugContent(content any) string {
+	if lobby.settings.StreamerMode {
+		return "(content hidden in streamer mode)"
+	}
+	return truncateContent(content)
 }
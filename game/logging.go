@@ -0,0 +1,25 @@
+package game
+
+import (
+	"github.com/google/uuid"
+	"log/slog"
+	"os"
+)
+
+// Logger is the logger each lobby's logger is derived from, see WithLobbyId.
+// It writes human-readable text by default; main swaps it for a JSON one in production so the logs can be parsed by log aggregation
+var Logger = NewLogger(false)
+
+// NewLogger creates a logger which writes to stdout, as JSON lines if json is true or as text otherwise
+func NewLogger(json bool) *slog.Logger {
+	options := &slog.HandlerOptions{AddSource: true}
+	if json {
+		return slog.New(slog.NewJSONHandler(os.Stdout, options))
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, options))
+}
+
+// WithLobbyId returns a logger which includes lobbyId in every entry it writes
+func WithLobbyId(logger *slog.Logger, lobbyId uuid.UUID) *slog.Logger {
+	return logger.With(slog.String("lobbyId", lobbyId.String()))
+}
@@ -0,0 +1,24 @@
+package game
+
+import (
+	"github.com/jhshelnu/wordcraft/icons"
+	"github.com/jhshelnu/wordcraft/words"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the word list and challenges are read from the data directory, relative to the root of the repository
+	if err := os.Chdir(".."); err != nil {
+		log.Fatal(err)
+	}
+	if err := words.Init(""); err != nil {
+		log.Fatal(err)
+	}
+	if err := icons.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(m.Run())
+}
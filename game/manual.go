@@ -0,0 +1,88 @@
+package game
+
+import (
+	"github.com/jhshelnu/wordcraft/words"
+	"strings"
+	"time"
+)
+
+// manualChallengeTimeout is how long the host has to choose a challenge in manual challenge mode, before one is generated as usual
+const manualChallengeTimeout = 30 * time.Second
+
+// awaitChallenge holds off on starting the current turn until the host chooses its challenge (see onProvideChallenge)
+// the turn's clock doesn't start until then, so the client whose turn it is doesn't lose any time
+func (lobby *Lobby) awaitChallenge() {
+	lobby.awaitingChallenge = true
+	lobby.currentChallenge = ""
+	lobby.currentTurnEnd = 0
+	lobby.turnExpired = nil
+	lobby.gracePeriodEnded = nil
+	lobby.stopClockSync()
+	lobby.challengeTimeout = time.After(manualChallengeTimeout)
+
+	lobby.BroadcastMessage(Message{Type: WaitingForChallenge, Content: WaitingForChallengeContent{
+		ClientId:  lobby.aliveClients[lobby.turnIndex].id,
+		TimeoutMs: manualChallengeTimeout.Milliseconds(),
+	}})
+}
+
+// changeTurnAfterAnswer moves the turn on once the current client's answer has been accepted
+// in manual challenge mode, this is when the host chooses the next challenge. turns which expire (or whose client leaves) are
+// moved on by changeTurn instead, with a generated challenge, so the game doesn't stall on the host
+func (lobby *Lobby) changeTurnAfterAnswer() {
+	if !lobby.settings.ManualChallengeMode {
+		lobby.changeTurn(false)
+		return
+	}
+
+	if lobby.passTurn(false) {
+		lobby.awaitChallenge()
+	}
+}
+
+// onProvideChallenge starts the turn being awaited with the challenge the host chose
+func (lobby *Lobby) onProvideChallenge(message Message) {
+	if !lobby.isHost(message.From) {
+		lobby.denyPermission(message)
+		return
+	}
+
+	challenge, err := DecodeContent[string](message)
+	if err != nil || lobby.status != InProgress || !lobby.awaitingChallenge {
+		return
+	}
+
+	challenge = strings.ToLower(strings.TrimSpace(challenge))
+	if !lobby.wordList().IsValidChallenge(challenge, words.ChallengeEasy) {
+		lobby.logger.Info("manual challenge rejected because it is too short or no words contain it", "client", lobby.clients[message.From].String(), "challenge", challenge)
+		lobby.SendToClient(message.From, Message{Type: WaitingForChallenge, Content: WaitingForChallengeContent{
+			ClientId:          lobby.aliveClients[lobby.turnIndex].id,
+			TimeoutMs:         manualChallengeTimeout.Milliseconds(),
+			RejectedChallenge: challenge,
+		}})
+		return
+	}
+
+	lobby.logger.Info("manual challenge provided", "client", lobby.clients[message.From].String(), "challenge", challenge)
+	lobby.stopAwaitingChallenge()
+	lobby.currentChallenge = challenge
+	lobby.startTurn()
+}
+
+// onChallengeTimeout generates the challenge the host didn't choose in time
+func (lobby *Lobby) onChallengeTimeout() {
+	lobby.challengeTimeout = nil
+	if lobby.status != InProgress || !lobby.awaitingChallenge {
+		return
+	}
+
+	lobby.logger.Info("no manual challenge provided in time, generating one instead", "timeoutMs", manualChallengeTimeout.Milliseconds())
+	lobby.stopAwaitingChallenge()
+	lobby.generateChallenge()
+	lobby.startTurn()
+}
+
+func (lobby *Lobby) stopAwaitingChallenge() {
+	lobby.awaitingChallenge = false
+	lobby.challengeTimeout = nil
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"testing"
+)
+
+// the host only chooses the challenge after an accepted answer. other turns get a generated one, so the game doesn't stall
+func TestManualChallengeOnlyAfterAcceptedAnswers(t *testing.T) {
+	settings := DefaultLobbySettings()
+	settings.ManualChallengeMode = true
+	settings.ReconnectGracePeriodMs = 0
+	lobby := newTestLobby(settings)
+	defer close(lobby.done)
+	host := joinTestClient(lobby)
+	for range 3 {
+		joinTestClient(lobby)
+	}
+
+	lobby.onMessage(Message{From: host.id, Type: StartGame})
+	if lobby.awaitingChallenge || lobby.currentChallenge == "" {
+		t.Fatalf("the first turn is waiting on the host, want it to start with a generated challenge")
+	}
+
+	lobby.onTurnExpired()
+	if lobby.awaitingChallenge || lobby.currentChallenge == "" {
+		t.Fatalf("the turn after an expired one is waiting on the host, want it to start with a generated challenge")
+	}
+
+	drainMessages(host)
+	lobby.changeTurnAfterAnswer()
+	if !lobby.awaitingChallenge {
+		t.Fatalf("the turn after an accepted answer started by itself, want it to wait on the host")
+	}
+	if messages := drainMessages(host); !containsType(messages, WaitingForChallenge) {
+		t.Errorf("the host was sent %v, want %s", messages, WaitingForChallenge)
+	}
+
+	// the client whose turn was waiting leaves, so the next turn goes ahead without the host
+	lobby.onClientLeave(lobby.aliveClients[lobby.turnIndex])
+	if lobby.awaitingChallenge || lobby.currentChallenge == "" {
+		t.Errorf("the turn after the waiting client left is waiting on the host, want it to start with a generated challenge")
+	}
+}
+
+func containsType(messages []Message, messageType messageType) bool {
+	for _, message := range messages {
+		if message.Type == messageType {
+			return true
+		}
+	}
+	return false
+}
@@ -1,53 +1,270 @@
 package game
 
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+//go:generate go run gen_message_types.go
 type messageType string
 
 //goland:noinspection GoNameStartsWithPackageName
 const (
-	StartGame      messageType = "start_game"      // the game has started
-	ClientDetails              = "client_details"  // sent to a newly connected client, indicating their id, the status of the game, etc
-	ClientJoined               = "client_joined"   // a new client has joined
-	ClientLeft                 = "client_left"     // a client has left
-	SubmitAnswer               = "submit_answer"   // when the client submits an answer
-	AnswerPreview              = "answer_preview"  // preview of the current answer (not submitted) so other clients can see
-	AnswerAccepted             = "answer_accepted" // the answer is accepted
-	AnswerRejected             = "answer_rejected" // the answer is not accepted
-	TurnExpired                = "turn_expired"    // client has run out of time
-	ClientsTurn                = "clients_turn"    // it's a new clients turn
-	GameOver                   = "game_over"       // the game is over
-	RestartGame                = "restart_game"    // sent from a client to initiate a game restart. sever then rebroadcasts to all clients to confirm
-	NameChange                 = "name_change"     // used by clients to indicate they want a new display name
-	Shutdown                   = "shutdown"        // tells the clients the server is being shutdown now
+	StartGame               messageType = "start_game"                // the game has started
+	ClientDetails                       = "client_details"            // sent to a newly connected client, indicating their id, the status of the game, etc
+	ClientJoined                        = "client_joined"             // a new client has joined
+	ClientLeft                          = "client_left"               // a client has left
+	SubmitAnswer                        = "submit_answer"             // when the client submits an answer
+	AnswerPreview                       = "answer_preview"            // preview of the current answer (not submitted) so other clients can see
+	AnswerAccepted                      = "answer_accepted"           // the answer is accepted
+	AnswerRejected                      = "answer_rejected"           // the answer is not accepted
+	TurnExpired                         = "turn_expired"              // client has run out of time
+	ClientsTurn                         = "clients_turn"              // it's a new clients turn
+	GameOver                            = "game_over"                 // the game is over
+	RestartGame                         = "restart_game"              // sent from a client to initiate a game restart. sever then rebroadcasts to all clients to confirm
+	NameChange                          = "name_change"               // used by clients to indicate they want a new display name
+	Shutdown                            = "shutdown"                  // tells the clients the server is being shutdown now
+	AdvanceToNextRound                  = "advance_to_next_round"     // tells the clients of a tournament lobby where the next round is being played
+	DifficultyIncreased                 = "difficulty_increased"      // the challenges have gotten harder
+	GracePeriodEnd                      = "grace_period_end"          // the client whose turn it is has had time to read the challenge
+	AliveClientsUpdated                 = "alive_clients_updated"     // the authoritative list of clients who are still alive
+	AdvanceToFinals                     = "advance_to_finals"         // tells the winner of a qualifying tournament lobby where the final game is being played
+	Negotiate                           = "negotiate"                 // sent by a client to choose the format of the messages sent to it, e.g. "msgpack"
+	DifficultyCapped                    = "difficulty_capped"         // sent when there are no challenges for the current difficulty, so easier challenges are being given instead
+	DoubleDown                          = "double_down"               // sent by the client whose turn it is to bet on their answer: double points if it's accepted, a penalty if not
+	DoubleDownActivated                 = "double_down_activated"     // sent when a client doubles down on their turn
+	ScoreUpdated                        = "score_updated"             // sent when a client's score changes
+	MessageRejected                     = "message_rejected"          // sent to a client when a message it sent is malformed, e.g. has the wrong type of content
+	ForkAvailable                       = "fork_available"            // sent to the alive clients when a new lobby has been forked from theirs, which they can choose to join
+	ChatMessage                         = "chat_message"              // a chat message, sent by a client and then broadcast to everyone in the lobby
+	InactivityWarning                   = "inactivity_warning"        // sent when a lobby waiting for players has been idle long enough that it will soon be closed
+	NewStreakRecord                     = "new_streak_record"         // sent when a client beats the longest streak of accepted answers this game
+	RequestCurrentState                 = "request_current_state"     // sent by a client which may have missed the start of the current turn, to have it sent again
+	CurrentState                        = "current_state"             // the current turn, sent in response to request_current_state. has the same content as clients_turn
+	TimeLimitReduced                    = "time_limit_reduced"        // sent in time attack lobbies when enough answers have been accepted that turns get shorter
+	ClientReconnecting                  = "client_reconnecting"       // a client disconnected mid-game, and has a while to reconnect before they are out
+	ClientReconnected                   = "client_reconnected"        // a client who disconnected mid-game has reconnected
+	PromoteObserver                     = "promote_observer"          // sent by a spectator once a game is over to play in the next one. the server then rebroadcasts it to all clients to confirm
+	ClockSync                           = "clock_sync"                // sent every few seconds during a turn, so clients can correct their countdown for clock drift
+	PermissionDenied                    = "permission_denied"         // sent to a client who tried to do something only the host is allowed to
+	TransferHost                        = "transfer_host"             // sent by the host to hand the role to another client. the server then broadcasts the new host to all clients (also sent when the host leaves)
+	WaitingForChallenge                 = "waiting_for_challenge"     // sent in manual challenge mode when a turn is waiting on the host to choose its challenge
+	ProvideChallenge                    = "provide_challenge"         // sent by the host in manual challenge mode to choose the challenge for the turn
+	KickClient                          = "kick_client"               // sent by the host to remove another client from the lobby
+	ClientKicked                        = "client_kicked"             // sent when the host removes a client from the lobby, including to the client being removed
+	LobbyFull                           = "lobby_full"                // sent to a client who tried to join a lobby which already has LobbySettings.MaxPlayers clients, before their connection is closed
+	NotEnoughPlayers                    = "not_enough_players"        // sent to a client who tried to start the game before LobbySettings.MinPlayers clients had joined
+	RoundStarted                        = "round_started"             // sent when the turn comes back around to the first client, starting a new round
+	RateLimited                         = "rate_limited"              // sent to a client whose message was dropped because they're sending messages too quickly
+	SyncRequest                         = "sync_request"              // sent by a client whose view of the lobby may have diverged from the server's, to have the whole state sent again as ClientDetails
+	SyncRateLimited                     = "sync_rate_limited"         // sent to a client which asked for a sync too soon after its last one
+	TeamLifeLost                        = "team_life_lost"            // in teams lobbies, a team's turn has run out, costing it a life
+	LivesUpdated                        = "lives_updated"             // a client has lost (or won back) a life
+	MultiplierRoundInactive             = "multiplier_round_inactive" // the multiplier round is over, so answers earn the usual points again
+	WordSuggestion                      = "word_suggestion"           // sent by a client in an educational lobby to be sent a few words containing the given challenge
+	WordSuggestions                     = "word_suggestions"          // the words sent in response to word_suggestion, only to the client who asked
+	Announcement                        = "announcement"              // sent by the host with some text, which is then broadcast to everyone in the lobby along with the host's name
+)
+
+type rejectionReason string
+
+// the reasons an answer can be rejected, sent to clients in AnswerRejectedContent
+const (
+	NotAWordRejection         rejectionReason = "not_a_word"          // the answer is not in the word list
+	SameAsChallengeRejection  rejectionReason = "same_as_challenge"   // the answer is the challenge itself
+	MissingChallengeRejection rejectionReason = "missing_challenge"   // the answer does not contain the challenge
+	WrongPositionRejection    rejectionReason = "wrong_position"      // the answer contains the challenge, but not at the position the lobby requires
+	AlreadyUsedRejection      rejectionReason = "already_used"        // the answer has already been accepted earlier in the game
+	BannedAnswerRejection     rejectionReason = "banned_answer"       // the answer is one of the lobby's banned answers
+	AlreadyUsedByYouRejection rejectionReason = "already_used_by_you" // the submitting client has already had the answer accepted this game, see LobbySettings.NoRepeat
+	TooFastRejection          rejectionReason = "too_fast"            // the answer was submitted too soon after the client's last one, see LobbySettings.MinAnswerIntervalMs. only sent to the submitting client
+)
+
+type leaveReason string
+
+// the reasons a client can leave a lobby, sent to clients in ClientLeftContent
+const (
+	DisconnectedLeaveReason       leaveReason = "disconnected"        // the client's connection closed
+	DeviceDisconnectedLeaveReason leaveReason = "device_disconnected" // the client is a hot seat, and the device it was played on disconnected
+	KickedLeaveReason             leaveReason = "kicked"              // the host removed the client from the lobby
+)
+
+type winReason string
+
+// the ways a game can be won, sent to clients in GameOverContent
+const (
+	AllEliminatedWin winReason = "all_eliminated" // in a teams lobby, the other team ran out of lives
+	OpponentsLeftWin winReason = "opponents_left" // every other client disconnected
+	TurnExpiredWin   winReason = "turn_expired"   // the last opponent's turn expired, leaving one client
+	SoloGameOverWin  winReason = "solo_game_over" // in a solo game, the only client's time ran out. nobody wins, the game only ends with their score
+	PointsToWinWin   winReason = "points_to_win"  // the client reached the lobby's LobbySettings.PointsToWin
+	MaxTurnsWin      winReason = "max_turns"      // the lobby's LobbySettings.MaxTurns were all played, and the client had the highest score
 )
 
+type shutdownReason string
+
+// the reasons a lobby can close, sent to clients in ShutdownContent
+const (
+	ServerRestartShutdown   shutdownReason = "server_shutdown"  // the server is restarting
+	IdleTimeoutShutdown     shutdownReason = "idle_timeout"     // nothing happened in the lobby for too long
+	AdminTerminatedShutdown shutdownReason = "admin_terminated" // an admin closed the lobby
+	AllPlayersLeftShutdown  shutdownReason = "all_players_left" // everyone left, so there is nobody to play
+)
+
+type messageDirection string
+
+const (
+	ClientToServer messageDirection = "client_to_server"
+	ServerToClient messageDirection = "server_to_client"
+	BothDirections messageDirection = "both"
+)
+
+// MessageTypeInfo describes a type of message for client SDK generators, see MessageTypes
+type MessageTypeInfo struct {
+	Direction   messageDirection `json:"direction"`   // who sends this type of message
+	Description string           `json:"description"` // what this type of message means
+}
+
 type Message struct {
 	From    int         // id of the Client in the lobby
 	Type    messageType // content of the message
 	Content any         // any additional info, e.g. which client joined, what their answer is, etc
 }
 
+type ClientLeftContent struct {
+	ClientId int         // the client who left
+	Reason   leaveReason // why they left
+}
+
+type AnswerPreviewContent struct {
+	ClientId int    // the client whose turn it is
+	Preview  string // what they have typed so far
+}
+
+type AnswerAcceptedContent struct {
+	ClientId int    // the client whose answer was accepted
+	Answer   string // the answer that was accepted
+}
+
 type ClientsTurnContent struct {
-	ClientId  int    // whose turn it is
-	Challenge string // what the challenge string is, e.g. "atr"
-	TurnEnd   int64  // milliseconds from unix epoch (UTC)
+	ClientId              int                    // whose turn it is
+	Challenge             string                 // what the challenge string is, e.g. "atr"
+	TurnEnd               int64                  // milliseconds from unix epoch (UTC)
+	ValidAnswerCount      int                    `json:",omitempty"` // how many answers would be accepted for the challenge (only sent in educational mode)
+	ChallengeOrigin       string                 `json:",omitempty"` // the language the challenge comes from, e.g. "Latin" (only sent in educational mode, when it's known)
+	Difficulty            string                 // the difficulty of the challenge, e.g. "Medium"
+	TurnLimitMs           int64                  // how long the turn lasts in total, in milliseconds (unlike TurnEnd, which is when it ends)
+	Scores                map[int]int            // every client's score, indexed by their id
+	UsedAnswers           int                    // how many different answers have been accepted so far this game
+	RoundNumber           int                    // which round of the game the turn is in, starting from 1
+	PracticeRoundActive   *PracticeRoundActive   `json:",omitempty"` // set while the game is in its practice rounds (see LobbySettings.PracticeRounds)
+	TeamId                int                    `json:",omitempty"` // the team of the client whose turn it is (only sent in teams lobbies)
+	MultiplierRoundActive *MultiplierRoundActive `json:",omitempty"` // set when answers accepted this turn earn extra points (see LobbySettings.MultiplierRoundInterval)
+}
+
+type PracticeRoundActive struct {
+	RemainingPracticeRounds int // how many practice turns are left, including this one
+}
+
+type MultiplierRoundActive struct {
+	Multiplier int // what the points for an accepted answer are multiplied by
+}
+
+type AnswerRejectedContent struct {
+	Answer   string          // the answer which was rejected
+	Reason   rejectionReason // why it was rejected
+	ClientId int             // the client who submitted it
+}
+
+type RoundStartedContent struct {
+	RoundNumber     int    // which round is starting, starting from 1
+	DifficultyLabel string // the difficulty of challenges this round, e.g. "Medium"
+}
+
+type DifficultyIncreasedContent struct {
+	NewDifficulty string // the difficulty of challenges from now on, e.g. "Medium"
+}
+
+type DifficultyCappedContent struct {
+	Reason string // why the challenges are easier than they should be, e.g. "no_hard_challenges"
+}
+
+type TimeLimitReducedContent struct {
+	NewLimitMs int64 // how long turns last from now on, in milliseconds
+}
+
+// GameOverContent is broadcast to all clients when the game ends
+type GameOverContent struct {
+	WinnerId              int             // the id of the client who won, or 0 if nobody did (see SoloGameOverWin)
+	WinnerName            string          // the display name of the winner, at the moment they won, or "" if nobody did
+	RarestWord            string          `json:",omitempty"` // the least common answer accepted this game (omitted if no answers were accepted)
+	RarestWordFrequency   float64         // the fraction of challenges RarestWord answers, see words.WordFrequency
+	LongestStreak         int             `json:",omitempty"` // the most answers in a row any client had accepted this game (omitted if no answers were accepted)
+	LongestStreakClientId int             `json:",omitempty"` // who had the longest streak
+	Reason                winReason       // how the game was won
+	Scores                map[int]int     // every client's final score, indexed by their id
+	FinalStandings        []StandingEntry // the clients who played, from the winner down, see Lobby.buildFinalStandings
+	HardestChallenges     []string        `json:",omitempty"` // the challenges with the highest share of rejected answers this game, hardest first (omitted if no answers were rejected)
+	WinningTeamId         int             `json:",omitempty"` // the team which won (only sent in teams lobbies, where WinnerId is the winning team's top scorer)
+}
+
+// StandingEntry is one client's place in GameOverContent.FinalStandings
+type StandingEntry struct {
+	ClientId           int
+	EliminatedAt       *time.Time `json:",omitempty"` // when the client went out (omitted for the winner)
+	SurvivalDurationMs int64      // how long the client lasted, from the start of the game until they went out (or it ended, for the winner)
+}
+
+// AliveClientsUpdatedContent is broadcast to all clients whenever a client is removed from the game
+type AliveClientsUpdatedContent struct {
+	AliveClientIds []int // the ids of the clients who are still alive, in turn order
+}
+
+// WordSuggestionResponse is sent to a client in response to a WordSuggestion message
+type WordSuggestionResponse struct {
+	Challenge   string   // the challenge the client asked about
+	Suggestions []string // up to wordSuggestionCount words containing the challenge, in random order. empty if there aren't any
+}
+
+// LivesUpdatedContent is broadcast whenever a player loses or wins back a life, see LobbySettings.StartingLives
+type LivesUpdatedContent struct {
+	ClientId       int
+	RemainingLives int // at 0 the client is out, and a TurnExpired follows
+}
+
+// TeamLifeLostContent is broadcast instead of TurnExpiredContent in teams lobbies, where running out of time costs the team a life
+type TeamLifeLostContent struct {
+	TeamId      int      // the team which lost a life
+	ClientId    int      // whose turn ran out
+	LivesLeft   int      // how many lives the team has left. at 0 the team is out, and a GameOver follows
+	Suggestions []string // some common words they could have answered with
 }
 
 type TurnExpiredContent struct {
-	EliminatedClientId int      // id of the client who just went out
-	Suggestions        []string // some common words they could have answered with
+	ClientId    int      // id of the client who just went out
+	Suggestions []string // some common words they could have answered with
 }
 
 // ClientDetailsContent is broadcast from the server to one particular client at the moment of connection
 // it's job is to catch the client up on details-- what their id is, the current state of the game, etc
 type ClientDetailsContent struct {
-	ClientId          int             // the id assigned to this client
-	Status            gameStatus      // the status of the game (if a client connects mid-game or when the game is over, this is how they'll know)
-	Clients           []ClientContent // details of the existing clients in the lobby
-	CurrentTurnId     int             // the id of the client whose turn it is (or 0 if not applicable)
-	CurrentChallenge  string          // what the current challenge is, or "" if there isn't one
-	CurrentAnswerPrev string          // what the client whose turn it is currently has typed in
-	TurnEnd           int64           // milliseconds from unix epoch (UTC), or 0 if not applicable
-	WinnersName       string          // name of the client who won (at the moment of winning), or "" if not applicable
+	ClientId            int                   // the id assigned to this client
+	Status              gameStatus            // the status of the game (if a client connects mid-game or when the game is over, this is how they'll know)
+	Clients             []ClientContent       // details of the existing clients in the lobby
+	CurrentTurnId       int                   // the id of the client whose turn it is (or 0 if not applicable)
+	CurrentChallenge    string                // what the current challenge is, or "" if there isn't one
+	CurrentAnswerPrev   string                // what the client whose turn it is currently has typed in
+	TurnEnd             int64                 // milliseconds from unix epoch (UTC), or 0 if not applicable
+	WinnersName         string                // name of the client who won (at the moment of winning), or "" if not applicable
+	MinPlayers          int                   // how many clients need to be in the lobby before the game can be started
+	SeatIds             []int                 `json:",omitempty"` // in hot seat lobbies, the ids of the seats played on this client's device
+	ChatHistory         []ChatMessageContent  // the most recent chat messages (up to chatHistorySent of them), oldest first
+	Announcements       []AnnouncementContent // the host's most recent announcements, oldest first
+	RejoinToken         string                // secret to pass back when reconnecting to this lobby, to take back this client's place (see LobbySettings.ReconnectGracePeriodMs)
+	Rejoined            bool                  // whether this client has taken back the place it had before disconnecting, rather than joining fresh
+	IsPasswordProtected bool                  // whether clients need a password to join the lobby
+	HostId              int                   // the id of the client who can start and restart the game
+	Lives               map[int]int           `json:",omitempty"` // how many lives each player has left this game, indexed by their id (omitted in teams lobbies)
 }
 
 // ClientJoinedContent is broadcast to all clients when a new client joins
@@ -56,6 +273,22 @@ type ClientJoinedContent struct {
 	DisplayName string // what their name is
 	IconName    string // which icon they are using
 	Alive       bool   // whether they are alive or not
+	IsSpectator bool   // whether they joined mid-game, and are only watching until the next game
+	HostId      int    // the id of the client who can start and restart the game (which may be the new client, if they're the first to join)
+}
+
+// AdvanceToNextRoundContent is broadcast to all clients of a tournament lobby once the tournament server has set up the next round
+type AdvanceToNextRoundContent struct {
+	NextLobbyId uuid.UUID // the lobby the next round will be played in
+}
+
+// AdvanceToFinalsContent is sent to the winner of a qualifying lobby in a tournament
+type AdvanceToFinalsContent struct {
+	FinalLobbyId uuid.UUID // the lobby the final game will be played in
+}
+
+type ForkAvailableContent struct {
+	NewLobbyId uuid.UUID // the lobby which was forked from this one
 }
 
 type ClientNameChange struct {
@@ -66,8 +299,106 @@ type ClientNameChange struct {
 // ClientContent is not currently sent as a standalone message content, but embedded
 // within ClientDetailsContent. It represents the current state of another client in the lobby
 type ClientContent struct {
-	Id          int
-	DisplayName string
-	IconName    string
-	Alive       bool
+	Id           int
+	DisplayName  string
+	IconName     string
+	Alive        bool
+	Reconnecting bool
+	IsSpectator  bool
+}
+
+type DoubleDownActivatedContent struct {
+	ClientId int // the client who doubled down on their current turn
+}
+
+type ScoreUpdatedContent struct {
+	ClientId int
+	Score    int
+}
+
+type kickReason string
+
+// the reasons a client can be kicked, sent to clients in ClientKickedContent
+const (
+	KickedByHostReason    kickReason = "kicked_by_host" // the host chose to remove the client
+	RateLimitedKickReason kickReason = "rate_limited"   // the client had too many messages dropped for sending them too quickly, see Client.Read
+)
+
+type ClientKickedContent struct {
+	ClientId int        // the client who was removed
+	Reason   kickReason // why they were removed
+}
+
+type RateLimitedContent struct {
+	RetryAfterMs int64 // how long until the client can send another message, in milliseconds
+}
+
+type PermissionDeniedContent struct {
+	MessageType messageType // the type of message which was ignored, e.g. "start_game"
+}
+
+type LobbyFullContent struct {
+	MaxPlayers int // how many clients the lobby allows
+}
+
+type NotEnoughPlayersContent struct {
+	Players    int // how many clients would play if the game was started now
+	MinPlayers int // how many are needed
+}
+
+type TransferHostContent struct {
+	HostId int // the id of the new host
+}
+
+type WaitingForChallengeContent struct {
+	ClientId          int    // whose turn the challenge is for
+	TimeoutMs         int64  // how long the host has to choose the challenge before one is chosen for them, in milliseconds
+	RejectedChallenge string `json:",omitempty"` // when only sent to the host, the challenge they provided which can't be used
+}
+
+type ClockSyncContent struct {
+	TurnEnd   int64 // milliseconds from unix epoch (UTC), the same as in ClientsTurnContent
+	ServerNow int64 // the server's current time, in milliseconds from unix epoch (UTC)
+}
+
+type ChatMessageContent struct {
+	From   int    // the id of the client who sent the message
+	Text   string // what they said
+	SentAt int64  // when the server received the message, in milliseconds from unix epoch (UTC)
+}
+
+// AnnouncementContent is broadcast to all clients when the host makes an announcement
+type AnnouncementContent struct {
+	Text     string // what the host announced
+	FromName string // the host's name at the moment of announcing
+	SentAt   int64  // when the server received the announcement, in milliseconds from unix epoch (UTC)
+}
+
+type ShutdownContent struct {
+	Reason shutdownReason // why the lobby is closing
+}
+
+type InactivityWarningContent struct {
+	SecondsRemaining int // how long until the lobby is closed, unless there's some activity first
+}
+
+type NewStreakRecordContent struct {
+	ClientId int // who set the record
+	Streak   int // how many answers in a row they have had accepted
+}
+
+// ClientReconnectingContent is broadcast when an alive client disconnects mid-game and their place is held for them
+type ClientReconnectingContent struct {
+	ClientId      int // the client who disconnected
+	GracePeriodMs int // how long they have to reconnect before they are treated as having left
+}
+
+// ClientReconnectedContent is broadcast when a client whose place was being held reconnects
+type ClientReconnectedContent struct {
+	ClientId int // the client who reconnected
+}
+
+// PromoteObserverContent is broadcast when a spectator has asked to play in the next game
+type PromoteObserverContent struct {
+	ClientId int // the spectator who will play in the next game
 }
@@ -0,0 +1,61 @@
+// Code generated by "go run gen_message_types.go"; DO NOT EDIT.
+
+package game
+
+// MessageTypes describes every type of message, keyed by the type's value
+var MessageTypes = map[string]MessageTypeInfo{
+	"start_game":                {Direction: BothDirections, Description: "the game has started"},
+	"client_details":            {Direction: ServerToClient, Description: "sent to a newly connected client, indicating their id, the status of the game, etc"},
+	"client_joined":             {Direction: ServerToClient, Description: "a new client has joined"},
+	"client_left":               {Direction: ServerToClient, Description: "a client has left"},
+	"submit_answer":             {Direction: BothDirections, Description: "when the client submits an answer"},
+	"answer_preview":            {Direction: BothDirections, Description: "preview of the current answer (not submitted) so other clients can see"},
+	"answer_accepted":           {Direction: ServerToClient, Description: "the answer is accepted"},
+	"answer_rejected":           {Direction: ServerToClient, Description: "the answer is not accepted"},
+	"turn_expired":              {Direction: ServerToClient, Description: "client has run out of time"},
+	"clients_turn":              {Direction: ServerToClient, Description: "it's a new clients turn"},
+	"game_over":                 {Direction: ServerToClient, Description: "the game is over"},
+	"restart_game":              {Direction: BothDirections, Description: "sent from a client to initiate a game restart. sever then rebroadcasts to all clients to confirm"},
+	"name_change":               {Direction: BothDirections, Description: "used by clients to indicate they want a new display name"},
+	"shutdown":                  {Direction: ServerToClient, Description: "tells the clients the server is being shutdown now"},
+	"advance_to_next_round":     {Direction: ServerToClient, Description: "tells the clients of a tournament lobby where the next round is being played"},
+	"difficulty_increased":      {Direction: ServerToClient, Description: "the challenges have gotten harder"},
+	"grace_period_end":          {Direction: ServerToClient, Description: "the client whose turn it is has had time to read the challenge"},
+	"alive_clients_updated":     {Direction: ServerToClient, Description: "the authoritative list of clients who are still alive"},
+	"advance_to_finals":         {Direction: ServerToClient, Description: "tells the winner of a qualifying tournament lobby where the final game is being played"},
+	"negotiate":                 {Direction: ClientToServer, Description: "sent by a client to choose the format of the messages sent to it, e.g. \"msgpack\""},
+	"difficulty_capped":         {Direction: ServerToClient, Description: "sent when there are no challenges for the current difficulty, so easier challenges are being given instead"},
+	"double_down":               {Direction: ClientToServer, Description: "sent by the client whose turn it is to bet on their answer: double points if it's accepted, a penalty if not"},
+	"double_down_activated":     {Direction: ServerToClient, Description: "sent when a client doubles down on their turn"},
+	"score_updated":             {Direction: ServerToClient, Description: "sent when a client's score changes"},
+	"message_rejected":          {Direction: ServerToClient, Description: "sent to a client when a message it sent is malformed, e.g. has the wrong type of content"},
+	"fork_available":            {Direction: ServerToClient, Description: "sent to the alive clients when a new lobby has been forked from theirs, which they can choose to join"},
+	"chat_message":              {Direction: BothDirections, Description: "a chat message, sent by a client and then broadcast to everyone in the lobby"},
+	"inactivity_warning":        {Direction: ServerToClient, Description: "sent when a lobby waiting for players has been idle long enough that it will soon be closed"},
+	"new_streak_record":         {Direction: ServerToClient, Description: "sent when a client beats the longest streak of accepted answers this game"},
+	"request_current_state":     {Direction: ClientToServer, Description: "sent by a client which may have missed the start of the current turn, to have it sent again"},
+	"current_state":             {Direction: ServerToClient, Description: "the current turn, sent in response to request_current_state. has the same content as clients_turn"},
+	"time_limit_reduced":        {Direction: ServerToClient, Description: "sent in time attack lobbies when enough answers have been accepted that turns get shorter"},
+	"client_reconnecting":       {Direction: ServerToClient, Description: "a client disconnected mid-game, and has a while to reconnect before they are out"},
+	"client_reconnected":        {Direction: ServerToClient, Description: "a client who disconnected mid-game has reconnected"},
+	"promote_observer":          {Direction: BothDirections, Description: "sent by a spectator once a game is over to play in the next one. the server then rebroadcasts it to all clients to confirm"},
+	"clock_sync":                {Direction: ServerToClient, Description: "sent every few seconds during a turn, so clients can correct their countdown for clock drift"},
+	"permission_denied":         {Direction: ServerToClient, Description: "sent to a client who tried to do something only the host is allowed to"},
+	"transfer_host":             {Direction: BothDirections, Description: "sent by the host to hand the role to another client. the server then broadcasts the new host to all clients (also sent when the host leaves)"},
+	"waiting_for_challenge":     {Direction: ServerToClient, Description: "sent in manual challenge mode when a turn is waiting on the host to choose its challenge"},
+	"provide_challenge":         {Direction: ClientToServer, Description: "sent by the host in manual challenge mode to choose the challenge for the turn"},
+	"kick_client":               {Direction: ClientToServer, Description: "sent by the host to remove another client from the lobby"},
+	"client_kicked":             {Direction: ServerToClient, Description: "sent when the host removes a client from the lobby, including to the client being removed"},
+	"lobby_full":                {Direction: ServerToClient, Description: "sent to a client who tried to join a lobby which already has LobbySettings.MaxPlayers clients, before their connection is closed"},
+	"not_enough_players":        {Direction: ServerToClient, Description: "sent to a client who tried to start the game before LobbySettings.MinPlayers clients had joined"},
+	"round_started":             {Direction: ServerToClient, Description: "sent when the turn comes back around to the first client, starting a new round"},
+	"rate_limited":              {Direction: ServerToClient, Description: "sent to a client whose message was dropped because they're sending messages too quickly"},
+	"sync_request":              {Direction: ClientToServer, Description: "sent by a client whose view of the lobby may have diverged from the server's, to have the whole state sent again as ClientDetails"},
+	"sync_rate_limited":         {Direction: ServerToClient, Description: "sent to a client which asked for a sync too soon after its last one"},
+	"team_life_lost":            {Direction: ServerToClient, Description: "in teams lobbies, a team's turn has run out, costing it a life"},
+	"lives_updated":             {Direction: ServerToClient, Description: "a client has lost (or won back) a life"},
+	"multiplier_round_inactive": {Direction: ServerToClient, Description: "the multiplier round is over, so answers earn the usual points again"},
+	"word_suggestion":           {Direction: ClientToServer, Description: "sent by a client in an educational lobby to be sent a few words containing the given challenge"},
+	"word_suggestions":          {Direction: ServerToClient, Description: "the words sent in response to word_suggestion, only to the client who asked"},
+	"announcement":              {Direction: BothDirections, Description: "sent by the host with some text, which is then broadcast to everyone in the lobby along with the host's name"},
+}
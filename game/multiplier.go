@@ -0,0 +1,17 @@
+package game
+
+const multiplierRoundPoints = 2 // what points are multiplied by during multiplier rounds, see LobbySettings.MultiplierRoundInterval
+
+// isMultiplierTurn returns whether answers accepted this turn earn multiplierRoundPoints times the usual points
+func (lobby *Lobby) isMultiplierTurn() bool {
+	interval := lobby.settings.MultiplierRoundInterval
+	return interval > 0 && lobby.turnCount > 0 && lobby.turnCount%interval == 0
+}
+
+// getPointsMultiplier returns what points for an answer accepted this turn are multiplied by
+func (lobby *Lobby) getPointsMultiplier() int {
+	if lobby.isMultiplierTurn() {
+		return multiplierRoundPoints
+	}
+	return 1
+}
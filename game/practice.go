@@ -0,0 +1,17 @@
+package game
+
+import "time"
+
+const practiceTurnLimit = 30 * time.Second // how long practice turns last, see LobbySettings.PracticeRounds
+
+// isPracticeTurn returns whether the current turn is one of the game's practice rounds
+// these are always easy and last practiceTurnLimit, after which the usual difficulty and turn limit progression takes over
+func (lobby *Lobby) isPracticeTurn() bool {
+	return lobby.remainingPracticeRounds() > 0
+}
+
+// remainingPracticeRounds returns how many practice turns are left in the game, counting the current one
+func (lobby *Lobby) remainingPracticeRounds() int {
+	// turnCount is 0 before the first turn has started, which is still before any practice has been done
+	return max(lobby.settings.PracticeRounds-max(lobby.turnCount, 1)+1, 0)
+}
@@ -0,0 +1,38 @@
+package game
+
+// recent is a ring buffer of the most recent entries of something in a lobby, e.g. chat messages
+type recent[T any] struct {
+	entries []T // fixed in size, see newRecent
+	head    int // where the next entry will be written
+	count   int // how many entries are stored, up to len(entries)
+}
+
+// newRecent creates a ring buffer which remembers up to size entries
+func newRecent[T any](size int) recent[T] {
+	return recent[T]{entries: make([]T, size)}
+}
+
+// add adds an entry to the history, overwriting the oldest one if the history is full
+func (history *recent[T]) add(entry T) {
+	size := len(history.entries)
+	history.entries[history.head] = entry
+	history.head = (history.head + 1) % size
+	history.count = min(history.count+1, size)
+}
+
+// flatten returns the stored entries from oldest to newest
+func (history *recent[T]) flatten() []T {
+	return history.latest(history.count)
+}
+
+// latest returns the n most recent entries (or every entry, if fewer are stored) from oldest to newest
+func (history *recent[T]) latest(n int) []T {
+	size := len(history.entries)
+	n = min(n, history.count)
+	entries := make([]T, 0, n)
+	oldest := (history.head - n + size) % size
+	for i := range n {
+		entries = append(entries, history.entries[(oldest+i)%size])
+	}
+	return entries
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecent(t *testing.T) {
+	history := newRecent[int](5)
+	for i := range 7 {
+		history.add(i)
+	}
+
+	if got, want := history.flatten(), []int{2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("flatten() = %v, want %v", got, want)
+	}
+	if got, want := history.latest(2), []int{5, 6}; !slices.Equal(got, want) {
+		t.Errorf("latest(2) = %v, want %v", got, want)
+	}
+	if got, want := history.latest(10), []int{2, 3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("latest(10) = %v, want %v", got, want)
+	}
+}
+
+// the lobby remembers chatHistorySize messages, but joining clients are only sent the last chatHistorySent
+func TestChatHistorySentToJoiningClients(t *testing.T) {
+	lobby := newTestLobby(DefaultLobbySettings())
+	for i := range chatHistorySize + 10 {
+		lobby.chatHistory.add(ChatMessageContent{From: 1, Text: "hi", SentAt: int64(i)})
+	}
+
+	if stored := len(lobby.chatHistory.flatten()); stored != chatHistorySize {
+		t.Errorf("the lobby remembers %d chat messages, want %d", stored, chatHistorySize)
+	}
+	sent := lobby.BuildClientDetails(1).ChatHistory
+	if len(sent) != chatHistorySent {
+		t.Fatalf("sent %d chat messages, want %d", len(sent), chatHistorySent)
+	}
+	if newest := sent[len(sent)-1].SentAt; newest != chatHistorySize+9 {
+		t.Errorf("the last chat message sent was sent at %d, want the newest one (%d)", newest, chatHistorySize+9)
+	}
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"slices"
+	"time"
+)
+
+// ReconnectingClient is an alive client who disconnected mid-game, whose place is being held in case they reconnect
+type ReconnectingClient struct {
+	client *Client     // the client as it was when it disconnected, holding its place in aliveClients
+	timer  *time.Timer // fires once the grace period is over, see onReconnectExpired
+}
+
+// ClaimRejoinToken returns the id of the client holding rejoinToken, if their place is still being held
+// each token can only be claimed once, so two connections can't both take the same place
+// like GetNextClientId, this is safe to call from any goroutine, so that the rejoining client can be given their old id when it's created
+func (lobby *Lobby) ClaimRejoinToken(rejoinToken string) (int, bool) {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	id, ok := lobby.rejoinTokens[rejoinToken]
+	delete(lobby.rejoinTokens, rejoinToken)
+	return id, ok
+}
+
+func (lobby *Lobby) setRejoinToken(rejoinToken string, clientId int) {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	lobby.rejoinTokens[rejoinToken] = clientId
+}
+
+func (lobby *Lobby) deleteRejoinToken(rejoinToken string) {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	delete(lobby.rejoinTokens, rejoinToken)
+}
+
+// holdForReconnect keeps a client's place in the game for LobbySettings.ReconnectGracePeriodMs after they disconnect,
+// so that they can pick up where they left off if they come back in time (e.g. after refreshing the page)
+// returns false if their place isn't held, in which case they have left for good
+func (lobby *Lobby) holdForReconnect(leavingClient *Client) bool {
+	if lobby.settings.ReconnectGracePeriodMs == 0 ||
+		lobby.settings.GameMode == HotSeatGameMode ||
+		lobby.status != InProgress ||
+		!slices.Contains(lobby.aliveClients, leavingClient) {
+		return false
+	}
+
+	gracePeriod := time.Duration(lobby.settings.ReconnectGracePeriodMs) * time.Millisecond
+	reconnectingClient := &ReconnectingClient{client: leavingClient}
+	reconnectingClient.timer = time.AfterFunc(gracePeriod, func() {
+		select {
+		case lobby.reconnectExpired <- reconnectingClient:
+		case <-lobby.Done():
+		}
+	})
+	lobby.reconnecting[leavingClient.id] = reconnectingClient
+	lobby.setRejoinToken(leavingClient.rejoinToken, leavingClient.id)
+
+	lobby.logger.Info("holding place in case the client reconnects", "client", leavingClient.String(), "gracePeriodMs", gracePeriod.Milliseconds())
+	lobby.BroadcastMessage(Message{Type: ClientReconnecting, Content: ClientReconnectingContent{
+		ClientId:      leavingClient.id,
+		GracePeriodMs: lobby.settings.ReconnectGracePeriodMs,
+	}})
+
+	// nobody can answer for them while they're gone, so move on to the next client (without eliminating them)
+	if lobby.aliveClients[lobby.turnIndex] == leavingClient {
+		lobby.changeTurn(false)
+	}
+	return true
+}
+
+// isReconnecting returns whether the client has disconnected but still has their place held
+func (lobby *Lobby) isReconnecting(client *Client) bool {
+	_, ok := lobby.reconnecting[client.id]
+	return ok
+}
+
+// nextConnectedIndex returns the first index in aliveClients, starting from index, of a client who isn't reconnecting
+// if every alive client is reconnecting, index is returned as is
+func (lobby *Lobby) nextConnectedIndex(index int) int {
+	for i := range len(lobby.aliveClients) {
+		candidate := (index + i) % len(lobby.aliveClients)
+		if !lobby.isReconnecting(lobby.aliveClients[candidate]) {
+			return candidate
+		}
+	}
+	return index
+}
+
+// onClientRejoin gives the rejoining client back the place held by reconnectingClient, along with everything they had this game
+func (lobby *Lobby) onClientRejoin(rejoiningClient *Client, reconnectingClient *ReconnectingClient) {
+	reconnectingClient.timer.Stop()
+	delete(lobby.reconnecting, rejoiningClient.id)
+
+	previous := reconnectingClient.client
+	rejoiningClient.setDisplayName(previous.displayName)
+	rejoiningClient.iconName = previous.iconName
+	rejoiningClient.score = previous.score
+	rejoiningClient.usedDoubleDown = previous.usedDoubleDown
+	rejoiningClient.streak = previous.streak
+	rejoiningClient.teamId = previous.teamId
+	if i := slices.Index(lobby.aliveClients, previous); i != -1 {
+		lobby.aliveClients[i] = rejoiningClient
+	}
+
+	lobby.logger.Info("client reconnected", "client", rejoiningClient.String())
+	lobby.claimHostIfVacant(rejoiningClient) // if everyone was gone, the host left too
+
+	clientDetails := lobby.BuildClientDetails(rejoiningClient.id)
+	clientDetails.RejoinToken = rejoiningClient.rejoinToken
+	clientDetails.Rejoined = true
+	rejoiningClient.write <- Message{Type: ClientDetails, Content: clientDetails}
+
+	// everyone else still has them in the lobby, so only the rejoining client needs to be told about joining
+	lobby.clients[rejoiningClient.id] = rejoiningClient
+	lobby.updateClientCount()
+	rejoiningClient.write <- Message{Type: ClientJoined, Content: ClientJoinedContent{
+		ClientId:    rejoiningClient.id,
+		DisplayName: rejoiningClient.displayName,
+		IconName:    rejoiningClient.iconName,
+		Alive:       slices.Contains(lobby.aliveClients, rejoiningClient),
+		HostId:      lobby.hostId,
+	}}
+	lobby.BroadcastMessage(Message{Type: ClientReconnected, Content: ClientReconnectedContent{ClientId: rejoiningClient.id}})
+
+	// if everyone was gone, the turn may be sitting with a client who is still reconnecting, or even with the rejoining client
+	switch {
+	case lobby.status != InProgress:
+	case lobby.isReconnecting(lobby.aliveClients[lobby.turnIndex]):
+		lobby.changeTurn(false)
+	case lobby.awaitingChallenge:
+		// the turn hasn't started yet, so there's nothing to catch up on
+	case lobby.aliveClients[lobby.turnIndex] == rejoiningClient:
+		rejoiningClient.write <- Message{Type: CurrentState, Content: lobby.buildClientsTurnContent()}
+	}
+}
+
+// onReconnectExpired handles a client who didn't reconnect within the grace period, so they are treated as having left
+func (lobby *Lobby) onReconnectExpired(reconnectingClient *ReconnectingClient) {
+	// the client may have rejoined (and possibly disconnected again) just as the timer fired
+	if lobby.reconnecting[reconnectingClient.client.id] != reconnectingClient {
+		return
+	}
+
+	delete(lobby.reconnecting, reconnectingClient.client.id)
+	lobby.deleteRejoinToken(reconnectingClient.client.rejoinToken)
+
+	lobby.logger.Info("client did not reconnect in time", "client", reconnectingClient.client.String())
+	lobby.removeClient(reconnectingClient.client)
+}
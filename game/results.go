@@ -0,0 +1,36 @@
+package game
+
+import "github.com/jhshelnu/wordcraft/storage"
+
+// recordGameResult keeps a summary of the game which winningClient just won, to be saved once the lobby ends
+// winningClient is nil if nobody won, e.g. in a solo game
+func (lobby *Lobby) recordGameResult(winningClient *Client) {
+	if lobby.store == nil {
+		return
+	}
+
+	winnerName := ""
+	if winningClient != nil {
+		winnerName = winningClient.displayName
+	}
+
+	lobby.gameResults = append(lobby.gameResults, storage.GameResult{
+		LobbyId:     lobby.Id,
+		StartedAt:   lobby.startedAt,
+		EndedAt:     lobby.endedAt,
+		WinnerName:  winnerName,
+		PlayerCount: lobby.startingPlayers,
+		TotalRounds: lobby.turnRounds,
+		WordsUsed:   len(lobby.usedAnswers),
+	})
+}
+
+// saveGameResults writes the results of every game played in this lobby to the store
+// this happens once the lobby has ended, so that writing to the store never holds up a game in progress
+func (lobby *Lobby) saveGameResults() {
+	for _, result := range lobby.gameResults {
+		if err := lobby.store.SaveGameResult(result); err != nil {
+			lobby.logger.Error("failed to save the result of the game", "endedAt", result.EndedAt, "error", err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package game_test
+
+import (
+	"github.com/jhshelnu/wordcraft/game"
+	"github.com/jhshelnu/wordcraft/game/testutil"
+	"testing"
+)
+
+func TestRoundStartedOncePerRotation(t *testing.T) {
+	const rotations = 3
+	answers := []string{"singing", "ringing", "bringing", "thing", "king", "sting", "swing", "wing", "during"}
+	challenges := make([]string, len(answers)+1)
+	for i := range challenges {
+		challenges[i] = "ing"
+	}
+
+	scenario := append(testutil.Join("alice", "bob", "carol"), game.StartAction{})
+	for i, answer := range answers {
+		scenario = append(scenario, game.SubmitAction{ClientId: i%3 + 1, Answer: answer})
+	}
+	result := testutil.Simulate(t, testutil.Settings(challenges...), scenario)
+
+	// the first round starts with the game, and every rotation after that (3 accepted answers, one from each client) starts exactly one more
+	var rounds []game.RoundStartedContent
+	accepted := 0
+	for _, message := range result.Events[1] {
+		switch content := message.Content.(type) {
+		case game.AnswerAcceptedContent:
+			accepted++
+		case game.RoundStartedContent:
+			if want := min(len(rounds), 1) * 3; accepted != want {
+				t.Errorf("round %d started after %d answers, want %d", content.RoundNumber, accepted, want)
+			}
+			accepted = 0
+			rounds = append(rounds, content)
+		}
+	}
+
+	if len(rounds) != rotations+1 {
+		t.Fatalf("sent %d RoundStarted messages, want %d", len(rounds), rotations+1)
+	}
+	for i, round := range rounds {
+		if round.RoundNumber != i+1 {
+			t.Errorf("RoundStarted %d was for round %d, want %d", i+1, round.RoundNumber, i+1)
+		}
+	}
+}
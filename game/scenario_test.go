@@ -0,0 +1,101 @@
+package game_test
+
+import (
+	"github.com/jhshelnu/wordcraft/game"
+	"github.com/jhshelnu/wordcraft/game/testutil"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	tests := []struct {
+		name         string
+		scenario     []game.Action
+		wantStatus   string // the status the game is left in
+		wantWinner   int    // the id of the winner, or 0 for none
+		wantReason   string // the reason in the GameOver message, or "" if the game isn't over
+		wantRejected []game.AnswerRejectedContent
+	}{
+		{
+			name: "two player elimination",
+			scenario: append(testutil.Join("alice", "bob"),
+				game.StartAction{},
+				game.SubmitAction{ClientId: 1, Answer: "singing"},
+				game.WaitTurnExpire{},
+			),
+			wantStatus: "Over",
+			wantWinner: 1,
+			wantReason: "turn_expired",
+		},
+		{
+			name: "opponent leaving",
+			scenario: append(testutil.Join("alice", "bob"),
+				game.StartAction{},
+				game.LeaveAction{ClientId: 2},
+			),
+			wantStatus: "Over",
+			wantWinner: 1,
+			wantReason: "opponents_left",
+		},
+		{
+			name: "rejected repeat",
+			scenario: append(testutil.Join("alice", "bob"),
+				game.StartAction{},
+				game.SubmitAction{ClientId: 1, Answer: "singing"},
+				game.SubmitAction{ClientId: 2, Answer: "singing"},
+			),
+			wantStatus:   "InProgress",
+			wantRejected: []game.AnswerRejectedContent{{Answer: "singing", Reason: game.AlreadyUsedRejection, ClientId: 2}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := testutil.Simulate(t, testutil.Settings("ing", "ing", "ing"), test.scenario)
+
+			if result.FinalStatus.String() != test.wantStatus {
+				t.Errorf("the game is %s, want %s", result.FinalStatus, test.wantStatus)
+			}
+
+			winner := 0
+			if result.Winner != nil {
+				winner = result.Winner.Id()
+			}
+			if winner != test.wantWinner {
+				t.Errorf("client %d won, want %d", winner, test.wantWinner)
+			}
+
+			// every client is sent the same GameOver, so it's enough to check the one the first client got
+			gameOvers := testutil.Contents[game.GameOverContent](result.Events[1])
+			switch {
+			case test.wantReason == "" && len(gameOvers) > 0:
+				t.Errorf("sent %d GameOver messages, want none", len(gameOvers))
+			case test.wantReason != "" && len(gameOvers) != 1:
+				t.Errorf("sent %d GameOver messages, want 1", len(gameOvers))
+			case test.wantReason != "" && string(gameOvers[0].Reason) != test.wantReason:
+				t.Errorf("the game was won by %s, want %s", gameOvers[0].Reason, test.wantReason)
+			}
+
+			rejected := testutil.Contents[game.AnswerRejectedContent](result.Events[1])
+			if len(rejected) != len(test.wantRejected) {
+				t.Fatalf("rejected %+v, want %+v", rejected, test.wantRejected)
+			}
+			for i := range rejected {
+				if rejected[i] != test.wantRejected[i] {
+					t.Errorf("rejection %d is %+v, want %+v", i, rejected[i], test.wantRejected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTurnExpiredNamesTheClient(t *testing.T) {
+	result := testutil.Simulate(t, testutil.Settings("ing", "ing", "ing"), append(testutil.Join("alice", "bob", "carol"),
+		game.StartAction{},
+		game.WaitTurnExpire{},
+	))
+
+	expired := testutil.Contents[game.TurnExpiredContent](result.Events[2])
+	if len(expired) != 1 || expired[0].ClientId != 1 {
+		t.Errorf("sent TurnExpired messages %+v, want one for client 1", expired)
+	}
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"path"
+	"strings"
+)
+
+// every message type clients can send has a JSON schema in the schemas directory, named after the type's value
+// messages are checked against their schema before they reach the lobby, so handlers can trust the shape of the content
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+var messageSchemas = compileMessageSchemas()
+
+// ValidationError is sent back to a client whose message did not match the schema for its type
+type ValidationError struct {
+	Type    messageType // the type of the message that was rejected, if it had one
+	Message string      // what was wrong with the message
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s message: %s", e.Type, e.Message)
+}
+
+func compileMessageSchemas() map[messageType]*jsonschema.Schema {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	schemas := make(map[messageType]*jsonschema.Schema, len(entries))
+	for _, entry := range entries {
+		fileName := path.Join("schemas", entry.Name())
+		source, err := schemaFiles.ReadFile(fileName)
+		if err != nil {
+			panic(err)
+		}
+		if err = compiler.AddResource(fileName, bytes.NewReader(source)); err != nil {
+			panic(fmt.Sprintf("invalid message schema %s: %v", fileName, err))
+		}
+		schemas[messageType(strings.TrimSuffix(entry.Name(), ".json"))] = compiler.MustCompile(fileName)
+	}
+	return schemas
+}
+
+// validateMessage checks a decoded (but untyped) message from a client against the schema for its type
+func validateMessage(raw any) error {
+	fields, ok := raw.(map[string]any)
+	if !ok {
+		return ValidationError{Message: "message must be an object"}
+	}
+
+	typeName, _ := fields["Type"].(string)
+	schema, ok := messageSchemas[messageType(typeName)]
+	if !ok {
+		return ValidationError{Type: messageType(typeName), Message: "unknown message type"}
+	}
+
+	if err := schema.Validate(raw); err != nil {
+		return ValidationError{Type: messageType(typeName), Message: describeSchemaError(err)}
+	}
+	return nil
+}
+
+// describeSchemaError summarises what was wrong with a message, without the schema locations jsonschema includes
+func describeSchemaError(err error) string {
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return err.Error()
+	}
+
+	// the most specific problems are at the leaves of the tree of causes
+	var problems []string
+	var collect func(*jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			problems = append(problems, fmt.Sprintf("'%s': %s", e.InstanceLocation, e.Message))
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(schemaErr)
+	return strings.Join(problems, "; ")
+}
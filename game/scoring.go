@@ -0,0 +1,112 @@
+package game
+
+import "unicode/utf8"
+
+const (
+	acceptedAnswerPoints = 1 // how many points a client earns for each accepted answer, before any length bonus
+	maxLengthBonusPoints = 5 // the most bonus points an answer can earn for being longer than the challenge
+	expiredTurnPenalty   = 1 // how many points a client loses when their turn expires
+)
+
+// onDoubleDown lets the client whose turn it is bet on their answer being accepted this turn
+// an accepted answer then earns double points, but a rejected answer or running out of time costs them points instead
+// each client can only double down once per game
+func (lobby *Lobby) onDoubleDown(message Message) {
+	if lobby.status != InProgress || lobby.aliveClients[lobby.turnIndex].id != message.From {
+		return
+	}
+
+	client := lobby.aliveClients[lobby.turnIndex]
+	if client.usedDoubleDown {
+		lobby.logger.Info("ignoring repeated double down", "type", DoubleDown, "client", client.String())
+		return
+	}
+
+	lobby.logger.Info("client doubled down", "client", client.String())
+	client.usedDoubleDown = true
+	lobby.doubleDownActive = true
+	lobby.BroadcastMessage(Message{Type: DoubleDownActivated, Content: DoubleDownActivatedContent{ClientId: client.id}})
+}
+
+// awardPoints gives the client points for an accepted answer, doubled if they doubled down this turn (and again in a multiplier round)
+// on top of the base points, an answer earns a bonus point for each letter it has beyond the challenge, up to maxLengthBonusPoints
+func (lobby *Lobby) awardPoints(client *Client, answer string) {
+	lengthBonus := utf8.RuneCountInString(answer) - utf8.RuneCountInString(lobby.currentChallenge)
+	points := (acceptedAnswerPoints + min(max(lengthBonus, 0), maxLengthBonusPoints)) * lobby.getPointsMultiplier()
+	if lobby.doubleDownActive {
+		points *= 2
+		lobby.doubleDownActive = false
+	}
+	lobby.setScore(client, client.score+points)
+}
+
+// loseDoubleDown takes points from the client if they doubled down this turn but didn't get an answer accepted
+func (lobby *Lobby) loseDoubleDown(client *Client) {
+	if !lobby.doubleDownActive {
+		return
+	}
+
+	lobby.doubleDownActive = false
+	lobby.setScore(client, max(client.score-acceptedAnswerPoints, 0))
+}
+
+// penalizeExpiredTurn takes points from the client for running out of time, on top of anything they lose for doubling down
+func (lobby *Lobby) penalizeExpiredTurn(client *Client) {
+	lobby.loseDoubleDown(client)
+	lobby.setScore(client, max(client.score-expiredTurnPenalty, 0))
+}
+
+// setScore does nothing unless the lobby has LobbySettings.ScoreTracking enabled, in which case every score stays at 0
+func (lobby *Lobby) setScore(client *Client, score int) {
+	if !lobby.settings.ScoreTracking {
+		return
+	}
+	client.score = score
+	lobby.BroadcastMessage(Message{Type: ScoreUpdated, Content: ScoreUpdatedContent{ClientId: client.id, Score: score}})
+}
+
+// extendStreak counts another accepted answer towards the client's streak, letting everyone know if it sets a new record for the game
+// a streak is how many answers in a row the client has had accepted, without any being rejected in between
+func (lobby *Lobby) extendStreak(client *Client) {
+	client.streak++
+	if client.streak <= lobby.longestStreakRecord {
+		return
+	}
+
+	lobby.longestStreakRecord = client.streak
+	lobby.longestStreakHolder = client.id
+	lobby.BroadcastMessage(Message{Type: NewStreakRecord, Content: NewStreakRecordContent{ClientId: client.id, Streak: client.streak}})
+}
+
+// breakStreak resets the client's streak after one of their answers is rejected
+func (lobby *Lobby) breakStreak(client *Client) {
+	client.streak = 0
+}
+
+// buildScores returns every client's score, indexed by their id
+func (lobby *Lobby) buildScores() map[int]int {
+	scores := make(map[int]int, len(lobby.clients)+len(lobby.reconnecting))
+	for id, client := range lobby.clients {
+		scores[id] = client.score
+	}
+	for id, reconnectingClient := range lobby.reconnecting {
+		scores[id] = reconnectingClient.client.score
+	}
+	return scores
+}
+
+// resetScores clears every client's double down and streak, along with the game's streak record, ready for a new game
+// scores are cleared too, unless the lobby carries them over from game to game (see LobbySettings.AccumulateScores)
+func (lobby *Lobby) resetScores() {
+	lobby.doubleDownActive = false
+	lobby.longestStreakRecord = 0
+	lobby.longestStreakHolder = 0
+	for _, client := range lobby.clients {
+		if !lobby.settings.AccumulateScores {
+			client.score = 0
+		}
+		client.usedDoubleDown = false
+		client.streak = 0
+		client.eliminatedAt = nil
+	}
+}
@@ -0,0 +1,278 @@
+package game
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// ChallengeMode determines what kind of challenge is given to the client whose turn it is
+type ChallengeMode string
+
+const (
+	StandardChallengeMode ChallengeMode = "standard" // challenges are pieces of words, e.g. "atr"
+	EmojiChallengeMode    ChallengeMode = "emoji"    // challenges are emojis, answers must contain the word the emoji represents
+)
+
+// GameMode determines how players connect to the lobby
+type GameMode string
+
+const (
+	StandardGameMode   GameMode = "standard"    // each player plays on their own device
+	HotSeatGameMode    GameMode = "hot_seat"    // every player shares one device, passing it along when their turn comes
+	TimeAttackGameMode GameMode = "time_attack" // turns get shorter as more answers are accepted, see getTimeAttackLimit
+)
+
+// LobbyMode determines whether clients play for themselves or as part of a team
+type LobbyMode string
+
+const (
+	SoloLobbyMode  LobbyMode = "solo"  // every client plays for themselves, and is out as soon as their turn runs out
+	TeamsLobbyMode LobbyMode = "teams" // clients are split into two teams, which share teamLives lives between their players
+)
+
+const (
+	minTurnSeconds = 5   // the lowest minimumTurnSeconds a lobby can be created with
+	maxTurnSeconds = 120 // the highest initialTurnSeconds a lobby can be created with
+)
+
+// LobbySettings holds the options a lobby was created with. They are fixed for the lifetime of the lobby
+type LobbySettings struct {
+	ChallengeMode             ChallengeMode `json:"challengeMode"`             // what kind of challenges are given each turn
+	MinPlayers                int           `json:"minPlayers"`                // how many clients need to be in the lobby before the game can be (re)started
+	TournamentId              uuid.UUID     `json:"tournamentId"`              // the tournament this lobby is a match in, or uuid.Nil if it isn't part of one
+	RoundNumber               int           `json:"roundNumber"`               // which round of the tournament this lobby's match is for
+	HideRejections            bool          `json:"hideRejections"`            // when true, rejected answers are only sent to the client who submitted them
+	GraceBeforeFirstPreviewMs int           `json:"graceBeforeFirstPreviewMs"` // how long clients have to read the challenge at the start of a turn before they are expected to type. 0 disables it
+	ChallengePosition         int           `json:"challengePosition"`         // the character index answers must contain the challenge at (0 for a prefix), or -1 for anywhere
+	TournamentMode            bool          `json:"tournamentMode"`            // when true, the winner of this lobby advances to the final lobby
+	TournamentFinalLobbyId    uuid.UUID     `json:"tournamentFinalLobbyId"`    // the lobby the final game is played in, when TournamentMode is enabled
+	TournamentFinal           bool          `json:"tournamentFinal"`           // when true, only winners sent on from TournamentMode lobbies can join, and the game starts once MinPlayers of them have
+	EducationalMode           bool          `json:"educationalMode"`           // when true, clients are shown extra information to help them learn, e.g. how many answers a challenge has
+	GameMode                  GameMode      `json:"gameMode"`                  // how players connect to the lobby
+	HotSeatPlayers            int           `json:"hotSeatPlayers"`            // how many players share the device, for the hot seat GameMode
+	WebSocketCloseTimeoutMs   int           `json:"webSocketCloseTimeoutMs"`   // how long to wait for clients to acknowledge the close frame sent when the lobby closes
+	ChallengeVault            []string      `json:"challengeVault"`            // challenges to give out in order at the start of each game, before falling back to random ones
+	StreamerMode              bool          `json:"streamerMode"`              // when true, display names are replaced with "Player <id>" in log output
+	ReconnectGracePeriodMs    int           `json:"reconnectGracePeriodMs"`    // how long alive clients who disconnect mid-game have to reconnect before they are out. 0 disables it
+	AccumulateScores          bool          `json:"accumulateScores"`          // when true, scores carry over when the game is restarted instead of starting again from 0
+	AllowReuse                bool          `json:"allowReuse"`                // when true, answers can be accepted again after they've already been used in the game
+	BannedAnswers             []string      `json:"bannedAnswers"`             // words which are never accepted as answers in this lobby, even if they're in the word list (compared ignoring case)
+	InitialTurnSeconds        int           `json:"initialTurnSeconds"`        // how long the first round's turns last. later rounds get shorter, down to MinimumTurnSeconds
+	MinimumTurnSeconds        int           `json:"minimumTurnSeconds"`        // the shortest turns get as the game goes on
+	EasyRounds                int           `json:"easyRounds"`                // how many rounds are played with easy challenges, before they become medium
+	ManualChallengeMode       bool          `json:"manualChallengeMode"`       // when true, the host chooses each turn's challenge, e.g. a teacher leading a class
+	MediumRounds              int           `json:"mediumRounds"`              // how many rounds are played with medium challenges, before they become hard
+	MaxPlayers                int           `json:"maxPlayers"`                // the most clients (including spectators) the lobby allows at once. anyone else is turned away
+	DailyChallenge            bool          `json:"dailyChallenge"`            // when true, games get the day's challenges in a fixed order (the same in every daily lobby), and results go on the daily leaderboard
+	BatchingDelayMs           int           `json:"batchingDelayMs"`           // how long to hold messages for, so that any sent in the meantime go in the same frame (as an array). 0 disables it
+	PracticeRounds            int           `json:"practiceRounds"`            // how many turns at the start of each game are easy and last practiceTurnLimit, whatever the other settings are
+	NoRepeat                  bool          `json:"noRepeat"`                  // when true, clients can't reuse an answer they've had accepted earlier in the game (even if AllowReuse lets others use it)
+	AdaptiveTimingEnabled     bool          `json:"adaptiveTimingEnabled"`     // when true, turns are lengthened by twice the current player's round-trip time (up to maxLatencyAllowance)
+	GameOverDelayMs           int           `json:"gameOverDelayMs"`           // how long to wait after the game ends before telling the clients who won, see Lobby.announceGameOver. 0 disables it
+	LobbyMode                 LobbyMode     `json:"lobbyMode"`                 // whether clients play for themselves or in teams
+	StartingLives             int           `json:"startingLives"`             // how many times each player's turn can run out before they're out of the game (not used in teams lobbies)
+	AnswerRestoresLife        bool          `json:"answerRestoresLife"`        // when true, an accepted answer wins back a lost life, up to StartingLives
+	MinAnswerIntervalMs       int           `json:"minAnswerIntervalMs"`       // how long a client has to wait between submitting answers in the same turn. 0 disables it
+	MultiplierRoundInterval   int           `json:"multiplierRoundInterval"`   // every this many turns, accepted answers earn multiplierRoundPoints times the points, e.g. 5 for every 5th turn. 0 disables it
+	ScoreTracking             bool          `json:"scoreTracking"`             // when true, clients earn (and lose) points during the game, see scoring.go
+	MaxTurns                  int           `json:"maxTurns"`                  // how many turns the game lasts before the highest scoring client wins. 0 disables it
+	MinTurnsBeforeElimination int           `json:"minTurnsBeforeElimination"` // how many turns at the start of each game nobody can be eliminated (or lose a life) in. 0 disables it
+	PointsToWin               int           `json:"pointsToWin"`               // how many points a client needs to win the game outright. 0 disables it
+}
+
+// DefaultLobbySettings returns the settings used for a lobby when the creator does not specify any
+func DefaultLobbySettings() LobbySettings {
+	return LobbySettings{
+		ChallengeMode:             StandardChallengeMode,
+		MinPlayers:                2,
+		MaxPlayers:                8,
+		GraceBeforeFirstPreviewMs: 3_000,
+		ChallengePosition:         -1,
+		GameMode:                  StandardGameMode,
+		WebSocketCloseTimeoutMs:   5_000,
+		ReconnectGracePeriodMs:    30_000,
+		InitialTurnSeconds:        25,
+		MinimumTurnSeconds:        16,
+		EasyRounds:                4,
+		MediumRounds:              6,
+		GameOverDelayMs:           500,
+		LobbyMode:                 SoloLobbyMode,
+		StartingLives:             3,
+		MinAnswerIntervalMs:       500,
+		ScoreTracking:             true,
+	}
+}
+
+// Validate checks the settings for invalid or contradictory values, returning every violation found (or nil if there are none)
+func (settings LobbySettings) Validate() []error {
+	var errs []error
+
+	switch settings.ChallengeMode {
+	case StandardChallengeMode, EmojiChallengeMode:
+	default:
+		errs = append(errs, fmt.Errorf("unknown challengeMode '%s'", settings.ChallengeMode))
+	}
+
+	if settings.MinPlayers < 1 {
+		errs = append(errs, fmt.Errorf("minPlayers must be at least 1, got %d", settings.MinPlayers))
+	}
+
+	if settings.MinPlayers > settings.MaxPlayers {
+		errs = append(errs, fmt.Errorf("minPlayers (%d) cannot be more than maxPlayers (%d)", settings.MinPlayers, settings.MaxPlayers))
+	}
+
+	if settings.GraceBeforeFirstPreviewMs < 0 {
+		errs = append(errs, fmt.Errorf("graceBeforeFirstPreviewMs cannot be negative, got %d", settings.GraceBeforeFirstPreviewMs))
+	}
+
+	if settings.ChallengePosition < -1 {
+		errs = append(errs, fmt.Errorf("challengePosition must be -1 (any position) or a character index, got %d", settings.ChallengePosition))
+	}
+
+	if settings.ChallengePosition >= 0 && settings.ChallengeMode == EmojiChallengeMode {
+		errs = append(errs, fmt.Errorf("challengePosition is not supported for the %s challengeMode", EmojiChallengeMode))
+	}
+
+	if settings.WebSocketCloseTimeoutMs < 0 {
+		errs = append(errs, fmt.Errorf("webSocketCloseTimeoutMs cannot be negative, got %d", settings.WebSocketCloseTimeoutMs))
+	}
+
+	if settings.BatchingDelayMs < 0 {
+		errs = append(errs, fmt.Errorf("batchingDelayMs cannot be negative, got %d", settings.BatchingDelayMs))
+	}
+
+	if settings.GameOverDelayMs < 0 {
+		errs = append(errs, fmt.Errorf("gameOverDelayMs cannot be negative, got %d", settings.GameOverDelayMs))
+	}
+
+	if settings.MinAnswerIntervalMs < 0 {
+		errs = append(errs, fmt.Errorf("minAnswerIntervalMs cannot be negative, got %d", settings.MinAnswerIntervalMs))
+	}
+
+	if settings.ReconnectGracePeriodMs < 0 {
+		errs = append(errs, fmt.Errorf("reconnectGracePeriodMs cannot be negative, got %d", settings.ReconnectGracePeriodMs))
+	}
+
+	if settings.MinimumTurnSeconds < minTurnSeconds {
+		errs = append(errs, fmt.Errorf("minimumTurnSeconds must be at least %d, got %d", minTurnSeconds, settings.MinimumTurnSeconds))
+	}
+
+	if settings.InitialTurnSeconds > maxTurnSeconds {
+		errs = append(errs, fmt.Errorf("initialTurnSeconds cannot be more than %d, got %d", maxTurnSeconds, settings.InitialTurnSeconds))
+	}
+
+	if settings.InitialTurnSeconds < settings.MinimumTurnSeconds {
+		errs = append(errs, fmt.Errorf("initialTurnSeconds (%d) cannot be less than minimumTurnSeconds (%d)", settings.InitialTurnSeconds, settings.MinimumTurnSeconds))
+	}
+
+	if settings.PracticeRounds < 0 {
+		errs = append(errs, fmt.Errorf("practiceRounds cannot be negative, got %d", settings.PracticeRounds))
+	}
+
+	if settings.StartingLives < 1 {
+		errs = append(errs, fmt.Errorf("startingLives must be at least 1, got %d", settings.StartingLives))
+	}
+
+	if settings.MultiplierRoundInterval < 0 {
+		errs = append(errs, fmt.Errorf("multiplierRoundInterval cannot be negative, got %d", settings.MultiplierRoundInterval))
+	}
+
+	if settings.MaxTurns < 0 {
+		errs = append(errs, fmt.Errorf("maxTurns cannot be negative, got %d", settings.MaxTurns))
+	}
+
+	if settings.MinTurnsBeforeElimination < 0 {
+		errs = append(errs, fmt.Errorf("minTurnsBeforeElimination cannot be negative, got %d", settings.MinTurnsBeforeElimination))
+	}
+
+	// the game would end before anyone could be eliminated
+	if settings.MaxTurns != 0 && settings.MinTurnsBeforeElimination != 0 && settings.MaxTurns < settings.MinTurnsBeforeElimination {
+		errs = append(errs, fmt.Errorf("maxTurns (%d) cannot be less than minTurnsBeforeElimination (%d)", settings.MaxTurns, settings.MinTurnsBeforeElimination))
+	}
+
+	if settings.PointsToWin < 0 {
+		errs = append(errs, fmt.Errorf("pointsToWin cannot be negative, got %d", settings.PointsToWin))
+	}
+
+	if settings.PointsToWin > 0 && !settings.ScoreTracking {
+		errs = append(errs, fmt.Errorf("pointsToWin requires scoreTracking to be enabled"))
+	}
+
+	if settings.EasyRounds < 0 {
+		errs = append(errs, fmt.Errorf("easyRounds cannot be negative, got %d", settings.EasyRounds))
+	}
+
+	if settings.MediumRounds < 0 {
+		errs = append(errs, fmt.Errorf("mediumRounds cannot be negative, got %d", settings.MediumRounds))
+	}
+
+	switch settings.GameMode {
+	case StandardGameMode, TimeAttackGameMode:
+	case HotSeatGameMode:
+		if settings.HotSeatPlayers < 2 {
+			errs = append(errs, fmt.Errorf("hotSeatPlayers must be at least 2 for the %s gameMode, got %d", HotSeatGameMode, settings.HotSeatPlayers))
+		}
+		if settings.HotSeatPlayers > settings.MaxPlayers {
+			errs = append(errs, fmt.Errorf("hotSeatPlayers (%d) cannot be more than maxPlayers (%d)", settings.HotSeatPlayers, settings.MaxPlayers))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown gameMode '%s'", settings.GameMode))
+	}
+
+	switch settings.LobbyMode {
+	case SoloLobbyMode:
+	case TeamsLobbyMode:
+		if settings.MinPlayers < 2 {
+			errs = append(errs, fmt.Errorf("minPlayers must be at least 2 for the %s lobbyMode, got %d", TeamsLobbyMode, settings.MinPlayers))
+		}
+		if settings.GameMode == HotSeatGameMode {
+			errs = append(errs, fmt.Errorf("the %s lobbyMode cannot be combined with the %s gameMode", TeamsLobbyMode, HotSeatGameMode))
+		}
+		if settings.DailyChallenge {
+			errs = append(errs, fmt.Errorf("dailyChallenge cannot be combined with the %s lobbyMode", TeamsLobbyMode))
+		}
+		if settings.TournamentMode || settings.TournamentId != uuid.Nil {
+			errs = append(errs, fmt.Errorf("tournaments cannot be played in the %s lobbyMode", TeamsLobbyMode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown lobbyMode '%s'", settings.LobbyMode))
+	}
+
+	if len(settings.ChallengeVault) > 0 && settings.ChallengeMode == EmojiChallengeMode {
+		errs = append(errs, fmt.Errorf("challengeVault is not supported for the %s challengeMode", EmojiChallengeMode))
+	}
+
+	if settings.DailyChallenge {
+		// everyone playing the daily challenge needs to get the same challenges
+		if settings.ChallengeMode == EmojiChallengeMode {
+			errs = append(errs, fmt.Errorf("dailyChallenge is not supported for the %s challengeMode", EmojiChallengeMode))
+		}
+		if settings.ChallengePosition >= 0 {
+			errs = append(errs, fmt.Errorf("dailyChallenge cannot be combined with challengePosition"))
+		}
+		if len(settings.ChallengeVault) > 0 {
+			errs = append(errs, fmt.Errorf("dailyChallenge cannot be combined with challengeVault"))
+		}
+		if settings.ManualChallengeMode {
+			errs = append(errs, fmt.Errorf("dailyChallenge cannot be combined with manualChallengeMode"))
+		}
+	}
+
+	if settings.TournamentMode && settings.TournamentFinalLobbyId == uuid.Nil {
+		errs = append(errs, fmt.Errorf("tournamentFinalLobbyId is required when tournamentMode is enabled"))
+	}
+
+	if settings.TournamentFinal && settings.TournamentMode {
+		errs = append(errs, fmt.Errorf("tournamentFinal cannot be combined with tournamentMode"))
+	}
+
+	if settings.TournamentFinal && settings.GameMode == HotSeatGameMode {
+		errs = append(errs, fmt.Errorf("tournamentFinal cannot be combined with the %s gameMode", HotSeatGameMode))
+	}
+
+	if settings.TournamentId != uuid.Nil && settings.RoundNumber < 1 {
+		errs = append(errs, fmt.Errorf("roundNumber must be at least 1 for tournament lobbies, got %d", settings.RoundNumber))
+	}
+
+	return errs
+}
@@ -0,0 +1,57 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTurnLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(settings *LobbySettings)
+		wantError string // a substring of the only error expected, or "" for none
+	}{
+		{"defaults", func(settings *LobbySettings) {}, ""},
+		{"max turns after eliminations start", func(settings *LobbySettings) {
+			settings.MaxTurns, settings.MinTurnsBeforeElimination = 10, 5
+		}, ""},
+		{"max turns before eliminations start", func(settings *LobbySettings) {
+			settings.MaxTurns, settings.MinTurnsBeforeElimination = 5, 10
+		}, "maxTurns (5) cannot be less than minTurnsBeforeElimination (10)"},
+		{"only min turns before elimination", func(settings *LobbySettings) {
+			settings.MinTurnsBeforeElimination = 10
+		}, ""},
+		{"points to win", func(settings *LobbySettings) {
+			settings.PointsToWin = 50
+		}, ""},
+		{"points to win without scores", func(settings *LobbySettings) {
+			settings.PointsToWin, settings.ScoreTracking = 50, false
+		}, "pointsToWin requires scoreTracking"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := DefaultLobbySettings()
+			test.configure(&settings)
+			errs := settings.Validate()
+
+			switch {
+			case test.wantError == "" && len(errs) > 0:
+				t.Errorf("got errors %v, want none", errs)
+			case test.wantError != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), test.wantError)):
+				t.Errorf("got errors %v, want one containing %q", errs, test.wantError)
+			}
+		})
+	}
+}
+
+// every violation is reported, not only the first one found
+func TestValidateReportsEveryViolation(t *testing.T) {
+	settings := DefaultLobbySettings()
+	settings.MaxTurns, settings.MinTurnsBeforeElimination = 5, 10
+	settings.PointsToWin, settings.ScoreTracking = 50, false
+
+	if errs := settings.Validate(); len(errs) != 2 {
+		t.Errorf("got errors %v, want 2", errs)
+	}
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// simulatedClientBuffer is how many messages a simulated client can be sent before the lobby would block
+const simulatedClientBuffer = 1_024
+
+// Action is one step of a scenario played out by SimulateLobby
+type Action interface {
+	apply(simulation *simulation) error
+}
+
+// JoinAction connects a new client to the lobby. clients are given ids in the order they join, starting from 1
+type JoinAction struct {
+	DisplayName string // the name to give the client, or "" to keep the default
+}
+
+// StartAction starts the game, as the first client to have joined
+type StartAction struct{}
+
+// SubmitAction submits an answer as the given client, and waits for it to be accepted or rejected
+type SubmitAction struct {
+	ClientId int
+	Answer   string
+}
+
+// LeaveAction disconnects the given client, as if they closed the page
+type LeaveAction struct {
+	ClientId int
+}
+
+// WaitTurnExpire runs the current turn's timer out, without waiting for it
+type WaitTurnExpire struct{}
+
+// LobbyResult is the outcome of SimulateLobby
+type LobbyResult struct {
+	Events      map[int][]Message // every message each client was sent, in order, keyed by the client's id
+	FinalStatus gameStatus        // the status of the game once the scenario was over
+	Winner      *Client           // the winner, or nil if the game isn't over (or ended without one)
+}
+
+type simulation struct {
+	lobby   *Lobby
+	clients []*Client // in the order they joined
+	result  *LobbyResult
+}
+
+// SimulateLobby plays out a scenario against a lobby with the given settings, without any websockets or HTTP server
+// handlers are called directly and timers never fire on their own (see WaitTurnExpire), so the result only depends on the
+// scenario and the challenges picked. words.Init and icons.Init must already have been called
+func SimulateLobby(settings LobbySettings, scenario []Action) (*LobbyResult, error) {
+	if errs := settings.Validate(); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	lobby := NewLobby(make(chan uuid.UUID, 1), settings, "", nil, nil)
+	defer close(lobby.done) // lets the validation workers give up on any results nobody is waiting for
+
+	sim := &simulation{lobby: lobby, result: &LobbyResult{Events: make(map[int][]Message)}}
+	for i, action := range scenario {
+		err := action.apply(sim)
+		sim.collectEvents()
+		if err != nil {
+			return sim.finish(), fmt.Errorf("action %d (%T): %w", i, action, err)
+		}
+	}
+	return sim.finish(), nil
+}
+
+// finish fills in how the game ended, for the result of the scenario
+func (sim *simulation) finish() *LobbyResult {
+	sim.result.FinalStatus = sim.lobby.status
+	if sim.lobby.status == Over && len(sim.lobby.aliveClients) > 0 {
+		sim.result.Winner = sim.lobby.aliveClients[0]
+	}
+	return sim.result
+}
+
+// collectEvents records the messages sent to each client since the last action
+func (sim *simulation) collectEvents() {
+	for _, client := range sim.clients {
+		for len(client.write) > 0 {
+			sim.result.Events[client.id] = append(sim.result.Events[client.id], <-client.write)
+		}
+	}
+}
+
+func (action JoinAction) apply(sim *simulation) error {
+	client := NewClient(sim.lobby.GetNextClientId(), nil, sim.lobby)
+	client.write = make(chan Message, simulatedClientBuffer)
+	if action.DisplayName != "" {
+		client.setDisplayName(action.DisplayName)
+	}
+
+	sim.clients = append(sim.clients, client)
+	sim.lobby.onClientJoin(client)
+	return nil
+}
+
+func (action StartAction) apply(sim *simulation) error {
+	if len(sim.clients) == 0 {
+		return errors.New("no clients have joined")
+	}
+
+	sim.lobby.onMessage(Message{From: sim.clients[0].id, Type: StartGame})
+	if sim.lobby.status != InProgress {
+		return fmt.Errorf("the game did not start, it is %s", sim.lobby.status)
+	}
+	return nil
+}
+
+func (action SubmitAction) apply(sim *simulation) error {
+	lobby := sim.lobby
+	if _, ok := lobby.clients[action.ClientId]; !ok {
+		return fmt.Errorf("no client with id %d", action.ClientId)
+	}
+
+	// answers are checked against the word list in the background, unless they are rejected up front
+	// so if the answer wasn't rejected, wait for the result and handle it just as the lobby goroutine would
+	rejectedAnswers := lobby.telemetry.rejectedAnswers
+	lobby.onMessage(Message{From: action.ClientId, Type: SubmitAnswer, Content: action.Answer})
+	if lobby.status != InProgress || lobby.aliveClients[lobby.turnIndex].id != action.ClientId || lobby.telemetry.rejectedAnswers > rejectedAnswers {
+		return nil
+	}
+
+	lobby.onAnswerValidated(<-lobby.validationResults)
+	lobby.sendPendingGameOver() // the answer may have won the game, see LobbySettings.PointsToWin
+	return nil
+}
+
+func (action LeaveAction) apply(sim *simulation) error {
+	client, ok := sim.lobby.clients[action.ClientId]
+	if !ok {
+		return fmt.Errorf("no client with id %d", action.ClientId)
+	}
+
+	sim.lobby.onClientLeave(client)
+	sim.lobby.sendPendingGameOver()
+	return nil
+}
+
+func (action WaitTurnExpire) apply(sim *simulation) error {
+	if sim.lobby.status != InProgress {
+		return fmt.Errorf("no turn to expire, the game is %s", sim.lobby.status)
+	}
+
+	sim.lobby.onTurnExpired()
+	sim.lobby.sendPendingGameOver() // like the turn's timer, the GameOver delay never fires on its own
+	return nil
+}
@@ -0,0 +1,47 @@
+package game
+
+import "time"
+
+// LobbyStateSnapshot is a point-in-time summary of a lobby, for tooling which can't (or shouldn't) join it over a websocket
+type LobbyStateSnapshot struct {
+	Status           gameStatus `json:"status"`
+	PlayerCount      int        `json:"playerCount"`
+	TurnRounds       int        `json:"turnRounds"`
+	CurrentTurnId    int        `json:"currentTurnId"`    // 0 if no game is in progress
+	CurrentChallenge string     `json:"currentChallenge"` // "" if there isn't one
+	TurnEndsAt       int64      `json:"turnEndsAt"`       // milliseconds from unix epoch (UTC), or 0 if no turn is in progress
+}
+
+// RequestState asks the lobby goroutine for a snapshot of the lobby's state, since its fields can't be read from any other goroutine
+// returns false if the lobby ends, or doesn't respond within timeout
+func (lobby *Lobby) RequestState(timeout time.Duration) (LobbyStateSnapshot, bool) {
+	deadline := time.After(timeout)
+	response := make(chan LobbyStateSnapshot, 1) // buffered, so the lobby isn't left waiting if we've given up by the time it responds
+
+	select {
+	case lobby.stateRequests <- response:
+	case <-lobby.Done():
+		return LobbyStateSnapshot{}, false
+	case <-deadline:
+		return LobbyStateSnapshot{}, false
+	}
+
+	select {
+	case snapshot := <-response:
+		return snapshot, true
+	case <-deadline:
+		return LobbyStateSnapshot{}, false
+	}
+}
+
+func (lobby *Lobby) onStateRequest(response chan<- LobbyStateSnapshot) {
+	details := lobby.BuildClientDetails(0)
+	response <- LobbyStateSnapshot{
+		Status:           details.Status,
+		PlayerCount:      len(lobby.clients),
+		TurnRounds:       lobby.turnRounds,
+		CurrentTurnId:    details.CurrentTurnId,
+		CurrentChallenge: details.CurrentChallenge,
+		TurnEndsAt:       details.TurnEnd,
+	}
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"slices"
+	"time"
+)
+
+// markEliminated records when the client went out of the game, for working out how long they survived
+func markEliminated(client *Client) {
+	now := time.Now()
+	client.eliminatedAt = &now
+}
+
+// buildFinalStandings ranks the clients who played the game which winningClient just won
+// the winner comes first, followed by everyone else in the reverse order they were eliminated (so the longest survivors rank highest)
+// like buildScores, this only covers clients who are still in the lobby
+func (lobby *Lobby) buildFinalStandings(winningClient *Client) []StandingEntry {
+	var players []*Client
+	for _, client := range lobby.clients {
+		if client == winningClient || client.eliminatedAt != nil {
+			players = append(players, client)
+		}
+	}
+	for _, reconnectingClient := range lobby.reconnecting {
+		if reconnectingClient.client == winningClient || reconnectingClient.client.eliminatedAt != nil {
+			players = append(players, reconnectingClient.client)
+		}
+	}
+	slices.SortFunc(players, func(c1, c2 *Client) int {
+		switch {
+		case c1 == winningClient:
+			return -1
+		case c2 == winningClient:
+			return 1
+		default:
+			return c2.eliminatedAt.Compare(*c1.eliminatedAt)
+		}
+	})
+
+	standings := make([]StandingEntry, 0, len(players))
+	for _, client := range players {
+		survivedUntil := lobby.endedAt
+		if client != winningClient {
+			survivedUntil = *client.eliminatedAt
+		}
+		standings = append(standings, StandingEntry{
+			ClientId:           client.id,
+			EliminatedAt:       client.eliminatedAt,
+			SurvivalDurationMs: survivedUntil.Sub(lobby.startedAt).Milliseconds(),
+		})
+	}
+	return standings
+}
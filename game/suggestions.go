@@ -0,0 +1,40 @@
+package game
+
+import (
+	"strings"
+)
+
+const wordSuggestionCount = 3 // how many words are sent in response to a WordSuggestion message
+
+// onWordSuggestion sends the client a few words containing the challenge they asked about, to help them learn in educational mode
+// each client can ask once per turn, and the client whose turn it is can't ask at all, since any words they're sent could answer their challenge
+// only challenges whose words are indexed can be asked about (see words.WordSet.IsIndexedChallenge), so a request never means searching every word
+func (lobby *Lobby) onWordSuggestion(message Message) {
+	challenge, err := DecodeContent[string](message)
+	if err != nil || !lobby.settings.EducationalMode {
+		return
+	}
+	challenge = strings.ToLower(challenge)
+
+	if lastTurn, ok := lobby.lastSuggestionTurn[message.From]; ok && lastTurn == lobby.turnCount {
+		lobby.telemetry.droppedMessages++
+		return
+	}
+	if lobby.status == InProgress && message.From == lobby.aliveClients[lobby.turnIndex].id {
+		lobby.telemetry.droppedMessages++
+		return
+	}
+	if !lobby.wordList().IsIndexedChallenge(challenge) {
+		lobby.telemetry.droppedMessages++
+		return
+	}
+	if lobby.lastSuggestionTurn == nil {
+		lobby.lastSuggestionTurn = make(map[int]int)
+	}
+	lobby.lastSuggestionTurn[message.From] = lobby.turnCount
+
+	lobby.SendToClient(message.From, Message{Type: WordSuggestions, Content: WordSuggestionResponse{
+		Challenge:   challenge,
+		Suggestions: lobby.wordList().SampleAnswers(challenge, wordSuggestionCount),
+	}})
+}
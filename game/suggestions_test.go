@@ -0,0 +1,60 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestWordSuggestion(t *testing.T) {
+	settings := DefaultLobbySettings()
+	settings.EducationalMode = true
+	settings.ChallengeVault = []string{"ing"}
+	lobby := newTestLobby(settings)
+	host := joinTestClient(lobby)
+	joinTestClient(lobby)
+	lobby.onMessage(Message{From: host.id, Type: StartGame})
+
+	current := lobby.aliveClients[lobby.turnIndex]
+	other := lobby.aliveClients[1-lobby.turnIndex]
+
+	tests := []struct {
+		name      string
+		from      *Client
+		challenge string
+		wantSent  bool
+	}{
+		{name: "current player asking about their challenge", from: current, challenge: "ing", wantSent: false},
+		{name: "current player asking about another challenge", from: current, challenge: "ion", wantSent: false},
+		{name: "challenge which isn't indexed", from: other, challenge: "zzzzq", wantSent: false},
+		{name: "other player", from: other, challenge: "ing", wantSent: true},
+		{name: "other player asking again this turn", from: other, challenge: "ion", wantSent: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			drainMessages(test.from)
+			lobby.onMessage(Message{From: test.from.id, Type: WordSuggestion, Content: test.challenge})
+
+			var response *WordSuggestionResponse
+			for _, message := range drainMessages(test.from) {
+				if content, ok := message.Content.(WordSuggestionResponse); ok {
+					response = &content
+				}
+			}
+			if (response != nil) != test.wantSent {
+				t.Fatalf("sent suggestions: %+v, want them sent: %t", response, test.wantSent)
+			}
+			if response != nil && len(response.Suggestions) != wordSuggestionCount {
+				t.Errorf("sent %d suggestions, want %d", len(response.Suggestions), wordSuggestionCount)
+			}
+		})
+	}
+}
+
+// drainMessages returns every message waiting in the client's write channel, see joinTestClient
+func drainMessages(client *Client) []Message {
+	var messages []Message
+	for len(client.write) > 0 {
+		messages = append(messages, <-client.write)
+	}
+	return messages
+}
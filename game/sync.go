@@ -0,0 +1,31 @@
+package game
+
+import (
+	"slices"
+	"time"
+)
+
+const syncRequestInterval = 10 * time.Second // how long a client has to wait between sync requests, since the full state is expensive to build and send
+
+// onSyncRequest sends a client everything it was sent when it joined, brought up to date, so that a client whose state has diverged
+// (e.g. after missing messages) can recover without reconnecting. see onRequestCurrentState for just re-sending the current turn
+func (lobby *Lobby) onSyncRequest(message Message) {
+	if lastSync, ok := lobby.lastSyncRequest[message.From]; ok {
+		if wait := syncRequestInterval - time.Since(lastSync); wait > 0 {
+			lobby.SendToClient(message.From, Message{Type: SyncRateLimited, Content: RateLimitedContent{RetryAfterMs: wait.Milliseconds()}})
+			return
+		}
+	}
+	if lobby.lastSyncRequest == nil {
+		lobby.lastSyncRequest = make(map[int]time.Time)
+	}
+	lobby.lastSyncRequest[message.From] = time.Now()
+
+	clientDetails := lobby.BuildClientDetails(message.From)
+	// when joining, the client isn't in the lobby yet, and its own card comes from the ClientJoined message instead
+	clientDetails.Clients = slices.DeleteFunc(clientDetails.Clients, func(c ClientContent) bool {
+		return c.Id == message.From
+	})
+	clientDetails.RejoinToken = lobby.clients[message.From].rejoinToken
+	lobby.SendToClient(message.From, Message{Type: ClientDetails, Content: clientDetails})
+}
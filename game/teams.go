@@ -0,0 +1,157 @@
+package game
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+const teamLives = 3                     // how many times a team's turn can run out before the whole team is out
+const teamAnswerBonus = 1 * time.Second // extra time for the next player on a team, after one of its players has an answer accepted
+
+// Team is one of the two sides in a teams lobby. which clients are on it is kept on each client, see Client.teamId,
+// since clients who reconnect are replaced by a new *Client
+type Team struct {
+	id           int  // 1 or 2
+	lives        int  // how many more of the team's turns can run out before it is out, see onTeamTurnExpired
+	bonusPending bool // whether the team's next turn gets teamAnswerBonus
+}
+
+func (lobby *Lobby) isTeamMode() bool {
+	return lobby.settings.LobbyMode == TeamsLobbyMode
+}
+
+func teamName(teamId int) string {
+	return fmt.Sprintf("Team %d", teamId)
+}
+
+// assignTeam puts a newly joined client on whichever team has fewer clients, so teams alternate as clients join
+func (lobby *Lobby) assignTeam(joiningClient *Client) {
+	if !lobby.isTeamMode() {
+		return
+	}
+	sizes := lobby.countTeamSizes(slices.Collect(maps.Values(lobby.clients)))
+	joiningClient.teamId = 1
+	if sizes[2] < sizes[1] {
+		joiningClient.teamId = 2
+	}
+}
+
+func (lobby *Lobby) countTeamSizes(clients []*Client) map[int]int {
+	sizes := make(map[int]int, 2)
+	for _, c := range clients {
+		sizes[c.teamId]++
+	}
+	return sizes
+}
+
+// resetTeams sets up the teams for a new game, once aliveClients holds the players
+// clients who left can make the teams uneven, so the latest joiners of the bigger team are moved over until they're even again,
+// then the turn order is arranged to alternate between the teams
+func (lobby *Lobby) resetTeams() {
+	if !lobby.isTeamMode() {
+		return
+	}
+
+	members := make(map[int][]*Client, 2)
+	for _, c := range lobby.aliveClients {
+		members[c.teamId] = append(members[c.teamId], c)
+	}
+	for len(members[1]) > len(members[2])+1 || len(members[2]) > len(members[1])+1 {
+		bigger, smaller := 1, 2
+		if len(members[2]) > len(members[1]) {
+			bigger, smaller = 2, 1
+		}
+		moving := members[bigger][len(members[bigger])-1]
+		members[bigger] = members[bigger][:len(members[bigger])-1]
+		moving.teamId = smaller
+		members[smaller] = append(members[smaller], moving)
+	}
+
+	lobby.aliveClients = lobby.aliveClients[:0]
+	for i := range max(len(members[1]), len(members[2])) {
+		for _, teamId := range []int{1, 2} {
+			if i < len(members[teamId]) {
+				lobby.aliveClients = append(lobby.aliveClients, members[teamId][i])
+			}
+		}
+	}
+	lobby.aliveTeams = []*Team{{id: 1, lives: teamLives}, {id: 2, lives: teamLives}}
+}
+
+// teamOf returns the team the client is playing for this game
+func (lobby *Lobby) teamOf(client *Client) *Team {
+	for _, team := range lobby.aliveTeams {
+		if team.id == client.teamId {
+			return team
+		}
+	}
+	return nil
+}
+
+// hasTeammate returns whether anyone else on the client's team is still in the game
+func (lobby *Lobby) hasTeammate(client *Client) bool {
+	return slices.ContainsFunc(lobby.aliveClients, func(c *Client) bool {
+		return c != client && c.teamId == client.teamId
+	})
+}
+
+// otherTeamId returns the id of the team playing against teamId
+func otherTeamId(teamId int) int {
+	return 3 - teamId
+}
+
+// onTeamTurnExpired costs the team whose turn ran out a life, rather than eliminating the client whose turn it was
+// once the team is out of lives, the other team has won
+func (lobby *Lobby) onTeamTurnExpired(expiredClient *Client) {
+	team := lobby.teamOf(expiredClient)
+	team.lives--
+	team.bonusPending = false
+	lobby.penalizeExpiredTurn(expiredClient)
+	lobby.BroadcastMessage(Message{Type: TeamLifeLost, Content: TeamLifeLostContent{
+		TeamId:      team.id,
+		ClientId:    expiredClient.id,
+		LivesLeft:   team.lives,
+		Suggestions: lobby.wordList().GetChallengeSuggestions(lobby.currentChallenge),
+	}})
+
+	if team.lives > 0 {
+		lobby.changeTurn(false)
+		return
+	}
+
+	lobby.setStatus(Over)
+	lobby.logger.Info("team ran out of lives, leaving the other team the winner",
+		"status", lobby.status, "team", teamName(team.id), "winningTeam", teamName(otherTeamId(team.id)))
+	lobby.declareTeamWinner(otherTeamId(team.id), AllEliminatedWin)
+}
+
+// declareTeamWinner wraps up a game which the team has just won
+// the team's top scorer stands in for it wherever a single winner is needed, e.g. the final standings and game results
+func (lobby *Lobby) declareTeamWinner(teamId int, reason winReason) {
+	var winningClient *Client
+	for _, c := range lobby.aliveClients {
+		if c.teamId == teamId && (winningClient == nil || c.score > winningClient.score) {
+			winningClient = c
+		}
+	}
+	lobby.declareWinner(winningClient, reason)
+}
+
+// rewardTeamAnswer gives the next player on the client's team teamAnswerBonus, for the answer the client just had accepted
+func (lobby *Lobby) rewardTeamAnswer(client *Client) {
+	if team := lobby.teamOf(client); team != nil {
+		team.bonusPending = true
+	}
+}
+
+// takeTeamBonus returns the extra time the client gets for their turn from their team's last accepted answer, if any
+func (lobby *Lobby) takeTeamBonus(client *Client) time.Duration {
+	team := lobby.teamOf(client)
+	if team == nil || !team.bonusPending {
+		return 0
+	}
+	team.bonusPending = false
+	return teamAnswerBonus
+}
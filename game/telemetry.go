@@ -0,0 +1,37 @@
+package game
+
+import (
+	"log/slog"
+	"os"
+	"time"
+)
+
+const telemetryInterval = 60 * time.Second // how often each lobby logs a telemetry summary
+
+// telemetryLogger writes telemetry summaries as JSON lines, so they can be picked up by log aggregation
+var telemetryLogger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// lobbyTelemetry counts what has happened in a lobby since its last telemetry summary
+type lobbyTelemetry struct {
+	acceptedAnswers int // answers accepted since the last summary
+	rejectedAnswers int // answers rejected since the last summary, for any reason
+	droppedMessages int // messages ignored since the last summary, e.g. ones with no handler or validation results for a turn that has ended
+	totalTurns      int // turns taken over the lifetime of the lobby, across every game. never reset
+}
+
+// logTelemetry logs a summary of the lobby's state and activity, then resets the counters covering the last interval
+func (lobby *Lobby) logTelemetry() {
+	telemetryLogger.Info("lobby telemetry",
+		slog.String("lobbyId", lobby.Id.String()),
+		slog.String("status", lobby.status.String()),
+		slog.Int("clientCount", len(lobby.clients)),
+		slog.Int("aliveCount", len(lobby.aliveClients)),
+		slog.Int("turnRounds", lobby.turnRounds),
+		slog.Int("totalTurns", lobby.telemetry.totalTurns),
+		slog.Int("acceptedAnswers", lobby.telemetry.acceptedAnswers),
+		slog.Int("rejectedAnswers", lobby.telemetry.rejectedAnswers),
+		slog.Int("droppedMessages", lobby.telemetry.droppedMessages),
+	)
+
+	lobby.telemetry = lobbyTelemetry{totalTurns: lobby.telemetry.totalTurns}
+}
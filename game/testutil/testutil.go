@@ -0,0 +1,50 @@
+// Package testutil has helpers for writing scenario tests against lobbies, played out with game.SimulateLobby
+package testutil
+
+import (
+	"github.com/jhshelnu/wordcraft/game"
+	"testing"
+)
+
+// Settings returns lobby settings suited to scenario tests
+// every game starts with the given challenges (see LobbySettings.ChallengeVault), so answers can be written ahead of time,
+// a single expired turn eliminates a player, and clients who leave are out straight away rather than held for reconnecting
+func Settings(challenges ...string) game.LobbySettings {
+	settings := game.DefaultLobbySettings()
+	settings.ChallengeVault = challenges
+	settings.StartingLives = 1
+	settings.ReconnectGracePeriodMs = 0
+	settings.MinAnswerIntervalMs = 0
+	return settings
+}
+
+// Join returns an action joining a client for each of names, in order. clients are given ids in the order they join, starting from 1
+func Join(names ...string) []game.Action {
+	actions := make([]game.Action, 0, len(names))
+	for _, name := range names {
+		actions = append(actions, game.JoinAction{DisplayName: name})
+	}
+	return actions
+}
+
+// Simulate plays out scenario with game.SimulateLobby, failing the test if any of its actions can't be applied
+func Simulate(tb testing.TB, settings game.LobbySettings, scenario []game.Action) *game.LobbyResult {
+	tb.Helper()
+
+	result, err := game.SimulateLobby(settings, scenario)
+	if err != nil {
+		tb.Fatalf("failed to simulate the lobby: %v", err)
+	}
+	return result
+}
+
+// Contents returns the content of each of messages which has a T as its content, in order
+func Contents[T any](messages []game.Message) []T {
+	var contents []T
+	for _, message := range messages {
+		if content, ok := message.Content.(T); ok {
+			contents = append(contents, content)
+		}
+	}
+	return contents
+}
@@ -0,0 +1,28 @@
+package game
+
+import "time"
+
+const timeAttackStartLimit = 20 * time.Second // the turn limit before any answers have been accepted
+const timeAttackReduction = 2 * time.Second   // how much shorter turns get every timeAttackAnswersPerReduction answers
+const timeAttackAnswersPerReduction = 5       // accepted answers (by anyone) between each reduction
+const timeAttackMinLimit = 5 * time.Second    // turns never get shorter than this
+
+// getTimeAttackLimit returns the turn limit for time attack lobbies, which shrinks as more answers are accepted this game
+func (lobby *Lobby) getTimeAttackLimit() time.Duration {
+	reductions := time.Duration(len(lobby.acceptedAnswers) / timeAttackAnswersPerReduction)
+	return max(timeAttackStartLimit-reductions*timeAttackReduction, timeAttackMinLimit)
+}
+
+// checkTimeLimitChange lets the clients know if the turn limit has been reduced since previousLimit
+func (lobby *Lobby) checkTimeLimitChange(previousLimit time.Duration) {
+	if lobby.settings.GameMode != TimeAttackGameMode {
+		return
+	}
+
+	newLimit := lobby.getTurnLimitDuration()
+	if newLimit < previousLimit {
+		lobby.logger.Info("turn limit reduced",
+			"fromMs", previousLimit.Milliseconds(), "toMs", newLimit.Milliseconds(), "answers", len(lobby.acceptedAnswers))
+		lobby.BroadcastMessage(Message{Type: TimeLimitReduced, Content: TimeLimitReducedContent{NewLimitMs: newLimit.Milliseconds()}})
+	}
+}
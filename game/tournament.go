@@ -0,0 +1,151 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+// TournamentWebhookURL is where lobbies that are part of a tournament report their results.
+// The tournament server responds with the id of the lobby for the next round. If empty, results are not reported
+var TournamentWebhookURL = ""
+
+var tournamentClient = &http.Client{Timeout: 10 * time.Second}
+
+// FindLobby, if set, looks up a lobby by its id, so that TournamentMode lobbies can register their winners with the final lobby
+// if nil, winners can't be sent on to the final
+var FindLobby func(lobbyId uuid.UUID) (*Lobby, bool)
+
+// tournamentResult is the body posted to TournamentWebhookURL when a tournament lobby's game is over
+type tournamentResult struct {
+	TournamentId uuid.UUID `json:"tournamentId"`
+	RoundNumber  int       `json:"roundNumber"`
+	WinnerId     int       `json:"winnerId"`
+	WinnerName   string    `json:"winnerName"`
+	FinalScore   int       `json:"finalScore"` // the winner's score at the end of the game
+}
+
+// tournamentResponse is what the tournament server sends back after receiving a tournamentResult
+type tournamentResponse struct {
+	NextLobbyId uuid.UUID `json:"nextLobbyId"`
+}
+
+// reportTournamentResult posts the winner of this lobby's game to the tournament server (if this lobby is part of one)
+// the request is made in the background so the lobby isn't blocked, and the next round's lobby is handed back via lobby.nextRound
+func (lobby *Lobby) reportTournamentResult(winningClient *Client) {
+	if lobby.settings.TournamentId == uuid.Nil {
+		return
+	}
+
+	if TournamentWebhookURL == "" {
+		lobby.logger.Warn("lobby is part of a tournament but no tournament webhook is configured", "tournamentId", lobby.settings.TournamentId)
+		return
+	}
+
+	result := tournamentResult{
+		TournamentId: lobby.settings.TournamentId,
+		RoundNumber:  lobby.settings.RoundNumber,
+		WinnerId:     winningClient.id,
+		WinnerName:   winningClient.displayName,
+		FinalScore:   winningClient.score,
+	}
+
+	go func() {
+		nextLobbyId, err := postTournamentResult(result)
+		if err != nil {
+			lobby.logger.Error("failed to report result to tournament", "tournamentId", result.TournamentId, "error", err)
+			return
+		}
+
+		select {
+		case lobby.nextRound <- nextLobbyId:
+		default:
+			lobby.logger.Warn("dropping next round lobby because the lobby is not accepting it", "nextLobbyId", nextLobbyId)
+		}
+	}()
+}
+
+func postTournamentResult(result tournamentResult) (uuid.UUID, error) {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	res, err := tournamentClient.Post(TournamentWebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return uuid.Nil, fmt.Errorf("tournament server responded with status %d", res.StatusCode)
+	}
+
+	var response tournamentResponse
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to parse tournament server response: %w", err)
+	}
+
+	return response.NextLobbyId, nil
+}
+
+func (lobby *Lobby) onNextRound(nextLobbyId uuid.UUID) {
+	lobby.logger.Info("tournament advancing to the next round", "tournamentId", lobby.settings.TournamentId, "nextLobbyId", nextLobbyId)
+	lobby.BroadcastMessage(Message{Type: AdvanceToNextRound, Content: AdvanceToNextRoundContent{NextLobbyId: nextLobbyId}})
+}
+
+// advanceWinnerToFinals sends the winner of a qualifying lobby on to the tournament's final lobby
+// the winner's rejoin token is registered with the final lobby first, since it only lets in the winners registered with it
+func (lobby *Lobby) advanceWinnerToFinals(winningClient *Client) {
+	if !lobby.settings.TournamentMode {
+		return
+	}
+
+	finalLobbyId := lobby.settings.TournamentFinalLobbyId
+	var finalLobby *Lobby
+	var found bool
+	if FindLobby != nil {
+		finalLobby, found = FindLobby(finalLobbyId)
+	}
+	if !found || !finalLobby.settings.TournamentFinal {
+		lobby.logger.Warn("not advancing winner because the final lobby can't take them", "client", winningClient.String(), "finalLobbyId", finalLobbyId)
+		return
+	}
+
+	finalLobby.RegisterFinalist(winningClient.rejoinToken)
+	lobby.logger.Info("advancing winner to the final lobby", "client", winningClient.String(), "finalLobbyId", finalLobbyId)
+	lobby.SendToClient(winningClient.id, Message{Type: AdvanceToFinals, Content: AdvanceToFinalsContent{FinalLobbyId: finalLobbyId}})
+}
+
+// RegisterFinalist lets the client holding rejoinToken join this lobby, which is a tournament's final (see LobbySettings.TournamentFinal)
+// like ClaimRejoinToken, this is safe to call from any goroutine, since it's called by the lobby the client won
+func (lobby *Lobby) RegisterFinalist(rejoinToken string) {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	lobby.finalists[rejoinToken] = struct{}{}
+}
+
+// IsFinalist returns whether the rejoin token was registered with this lobby by RegisterFinalist
+// like ClaimRejoinToken, this is safe to call from any goroutine, so that anyone else can be turned away before they join
+func (lobby *Lobby) IsFinalist(rejoinToken string) bool {
+	lobby.clientIdMutex.Lock()
+	defer lobby.clientIdMutex.Unlock()
+
+	_, ok := lobby.finalists[rejoinToken]
+	return ok
+}
+
+// startFinalIfReady starts a tournament final once LobbySettings.MinPlayers winners have joined it
+// there's no host to start it, since every finalist arrives on their own
+func (lobby *Lobby) startFinalIfReady() {
+	if !lobby.settings.TournamentFinal || lobby.status != WaitingForPlayers || len(lobby.aliveClients) < lobby.settings.MinPlayers {
+		return
+	}
+
+	lobby.logger.Info("enough finalists have arrived, starting the final", "players", len(lobby.aliveClients))
+	lobby.startGame()
+}
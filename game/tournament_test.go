@@ -0,0 +1,105 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReportTournamentResult(t *testing.T) {
+	nextLobbyId := uuid.New()
+	results := make(chan tournamentResult, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result tournamentResult
+		if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+			t.Errorf("failed to parse the tournament result: %v", err)
+		}
+		results <- result
+		_ = json.NewEncoder(w).Encode(tournamentResponse{NextLobbyId: nextLobbyId})
+	}))
+	defer server.Close()
+	TournamentWebhookURL = server.URL
+	defer func() { TournamentWebhookURL = "" }()
+
+	settings := DefaultLobbySettings()
+	settings.TournamentId = uuid.New()
+	settings.RoundNumber = 2
+	lobby := newTestLobby(settings)
+	defer close(lobby.done)
+	winner := joinTestClient(lobby)
+	winner.score = 42
+
+	lobby.reportTournamentResult(winner)
+
+	want := tournamentResult{
+		TournamentId: settings.TournamentId,
+		RoundNumber:  2,
+		WinnerId:     winner.id,
+		WinnerName:   winner.displayName,
+		FinalScore:   42,
+	}
+	if result := <-results; result != want {
+		t.Errorf("reported %+v, want %+v", result, want)
+	}
+	if got := <-lobby.nextRound; got != nextLobbyId {
+		t.Errorf("the next round is in lobby %s, want %s", got, nextLobbyId)
+	}
+}
+
+func TestAdvanceWinnersToFinals(t *testing.T) {
+	finalSettings := DefaultLobbySettings()
+	finalSettings.TournamentFinal = true
+	finalLobby := newTestLobby(finalSettings)
+	defer close(finalLobby.done)
+	FindLobby = func(lobbyId uuid.UUID) (*Lobby, bool) { return finalLobby, lobbyId == finalLobby.Id }
+	defer func() { FindLobby = nil }()
+
+	qualifyingSettings := DefaultLobbySettings()
+	qualifyingSettings.TournamentMode = true
+	qualifyingSettings.TournamentFinalLobbyId = finalLobby.Id
+	var winnerTokens []string
+	for range finalSettings.MinPlayers {
+		qualifyingLobby := newTestLobby(qualifyingSettings)
+		defer close(qualifyingLobby.done)
+		winner := joinTestClient(qualifyingLobby)
+		drainMessages(winner)
+		qualifyingLobby.advanceWinnerToFinals(winner)
+		winnerTokens = append(winnerTokens, winner.rejoinToken)
+
+		if messages := drainMessages(winner); len(messages) != 1 || messages[0].Type != AdvanceToFinals {
+			t.Errorf("the winner was sent %v, want only %s", messages, AdvanceToFinals)
+		}
+	}
+
+	// anyone who didn't win a qualifying lobby is turned away
+	conn, peer := newTestConn(t)
+	var joinErr JoinError
+	if err := JoinClientToLobby(conn, finalLobby, uuid.NewString(), ClientOptions{}); !errors.As(err, &joinErr) || joinErr.Code != NotFinalist {
+		t.Fatalf("JoinClientToLobby returned %v, want a JoinError with code %s", err, NotFinalist)
+	}
+	var closeErr *websocket.CloseError
+	if _, _, err := peer.ReadMessage(); !errors.As(err, &closeErr) || closeErr.Text != string(NotFinalist) {
+		t.Errorf("the connection was closed with %v, want the reason %s", err, NotFinalist)
+	}
+
+	// the final starts by itself once enough winners have joined
+	for i, token := range winnerTokens {
+		if finalLobby.status != WaitingForPlayers {
+			t.Fatalf("the final is %s with %d finalists, want it to wait for %d", finalLobby.status, i, finalSettings.MinPlayers)
+		}
+		if !finalLobby.IsFinalist(token) {
+			t.Fatalf("winner %d was not registered with the final lobby", i+1)
+		}
+		finalist := NewClient(finalLobby.GetNextClientId(), nil, finalLobby)
+		finalist.rejoinToken = token
+		finalist.write = make(chan Message, 100)
+		finalLobby.onClientJoin(finalist)
+	}
+	if finalLobby.status != InProgress {
+		t.Errorf("the final is %s once every winner has joined, want %s", finalLobby.status, InProgress)
+	}
+}
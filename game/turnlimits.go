@@ -0,0 +1,36 @@
+package game
+
+// isProtectedTurn returns whether the current turn is too early in the game for anyone to be eliminated (or lose a life)
+// see LobbySettings.MinTurnsBeforeElimination
+func (lobby *Lobby) isProtectedTurn() bool {
+	return lobby.turnCount <= lobby.settings.MinTurnsBeforeElimination
+}
+
+// reachedMaxTurns returns whether every turn the lobby allows has been played, see LobbySettings.MaxTurns
+func (lobby *Lobby) reachedMaxTurns() bool {
+	return lobby.settings.MaxTurns > 0 && lobby.turnCount >= lobby.settings.MaxTurns
+}
+
+// reachedPointsToWin returns whether the client has scored enough points to win the game, see LobbySettings.PointsToWin
+func (lobby *Lobby) reachedPointsToWin(client *Client) bool {
+	return lobby.settings.PointsToWin > 0 && client.score >= lobby.settings.PointsToWin
+}
+
+// declareScoreWinner wraps up a game which ended on points rather than eliminations
+// the alive client with the highest score wins (the earliest in turn order, if there's a tie), or their team in a teams lobby
+func (lobby *Lobby) declareScoreWinner(reason winReason) {
+	lobby.setStatus(Over)
+	var winningClient *Client
+	for _, c := range lobby.aliveClients {
+		if winningClient == nil || c.score > winningClient.score {
+			winningClient = c
+		}
+	}
+
+	lobby.logger.Info("game ended on points", "status", lobby.status, "reason", reason, "winner", winningClient.String())
+	if lobby.isTeamMode() {
+		lobby.declareTeamWinner(winningClient.teamId, reason)
+		return
+	}
+	lobby.declareWinner(winningClient, reason)
+}
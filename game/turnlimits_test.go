@@ -0,0 +1,64 @@
+package game_test
+
+import (
+	"github.com/jhshelnu/wordcraft/game"
+	"github.com/jhshelnu/wordcraft/game/testutil"
+	"testing"
+)
+
+func TestTurnLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		configure  func(settings *game.LobbySettings)
+		scenario   []game.Action
+		wantWinner int    // the id of the winner
+		wantReason string // the reason in the GameOver message
+	}{
+		{
+			name: "points to win",
+			configure: func(settings *game.LobbySettings) {
+				settings.PointsToWin = 5
+			},
+			scenario: append(testutil.Join("alice", "bob"),
+				game.StartAction{},
+				game.SubmitAction{ClientId: 1, Answer: "singing"},
+			),
+			wantWinner: 1,
+			wantReason: "points_to_win",
+		},
+		{
+			name: "max turns after a protected turn",
+			configure: func(settings *game.LobbySettings) {
+				settings.MaxTurns = 2
+				settings.MinTurnsBeforeElimination = 2
+			},
+			scenario: append(testutil.Join("alice", "bob"),
+				game.StartAction{},
+				game.SubmitAction{ClientId: 1, Answer: "singing"},
+				game.WaitTurnExpire{}, // bob isn't eliminated this early, but the game is out of turns
+			),
+			wantWinner: 1,
+			wantReason: "max_turns",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := testutil.Settings("ing", "ing", "ing")
+			test.configure(&settings)
+			result := testutil.Simulate(t, settings, test.scenario)
+
+			if result.FinalStatus != game.Over {
+				t.Errorf("the game is %s, want %s", result.FinalStatus, game.Over)
+			}
+			if result.Winner == nil || result.Winner.Id() != test.wantWinner {
+				t.Errorf("client %v won, want %d", result.Winner, test.wantWinner)
+			}
+
+			gameOvers := testutil.Contents[game.GameOverContent](result.Events[1])
+			if len(gameOvers) != 1 || string(gameOvers[0].Reason) != test.wantReason {
+				t.Errorf("sent GameOver messages %+v, want one won by %s", gameOvers, test.wantReason)
+			}
+		})
+	}
+}
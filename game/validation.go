@@ -0,0 +1,64 @@
+package game
+
+import (
+	"github.com/jhshelnu/wordcraft/words"
+	"sync"
+)
+
+const validationWorkers = 4 // how many goroutines validate answers, shared between all lobbies
+
+var validationRequests = make(chan validationRequest, 256) // answers waiting to be validated by a worker
+var startValidationWorkers sync.Once
+
+// validationRequest asks a worker to check whether answer is a word. The result is sent to results
+type validationRequest struct {
+	answer    string
+	wordSet   *words.WordSet // the word list of the lobby the answer was submitted in
+	clientId  int            // the client who submitted the answer
+	turnCount int            // which turn the answer was submitted during, so results for past turns can be ignored
+	results   chan<- validationResult
+	done      <-chan struct{} // closed if the lobby ends, in which case nobody is waiting for the result
+}
+
+type validationResult struct {
+	answer    string
+	clientId  int
+	turnCount int
+	valid     bool // whether the answer is in the word list
+}
+
+// validateAnswer checks answer against the word list in the background, so the lobby goroutine isn't blocked by it
+// the result is handled by Lobby.onAnswerValidated
+func (lobby *Lobby) validateAnswer(answer string, clientId int) {
+	startValidationWorkers.Do(func() {
+		for range validationWorkers {
+			go validationWorker()
+		}
+	})
+
+	lobby.validationPending[clientId] = answer
+	validationRequests <- validationRequest{
+		answer:    answer,
+		wordSet:   lobby.wordList(),
+		clientId:  clientId,
+		turnCount: lobby.turnCount,
+		results:   lobby.validationResults,
+		done:      lobby.Done(),
+	}
+}
+
+func validationWorker() {
+	for request := range validationRequests {
+		result := validationResult{
+			answer:    request.answer,
+			clientId:  request.clientId,
+			turnCount: request.turnCount,
+			valid:     request.wordSet.IsValidWord(request.answer),
+		}
+
+		select {
+		case request.results <- result:
+		case <-request.done:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"github.com/jhshelnu/wordcraft/words"
+	"testing"
+)
+
+// benchmarkAnswers is a mix of words and non-words, like the answers players submit
+var benchmarkAnswers = []string{"apple", "xylophone", "qqzx", "quickly", "abcdefg", "mountain", "zzzz", "strength"}
+
+// BenchmarkValidationPool measures how many answers per second the worker pool validates for a single lobby
+func BenchmarkValidationPool(b *testing.B) {
+	lobby := newTestLobby(DefaultLobbySettings())
+	defer close(lobby.done)
+
+	drained := make(chan struct{})
+	go func() {
+		for range b.N {
+			<-lobby.validationResults
+		}
+		close(drained)
+	}()
+
+	b.ResetTimer()
+	for i := range b.N {
+		lobby.validateAnswer(benchmarkAnswers[i%len(benchmarkAnswers)], i)
+	}
+	<-drained
+}
+
+// BenchmarkValidationInline is the baseline for the pool: checking answers on the lobby goroutine, as it was done before
+func BenchmarkValidationInline(b *testing.B) {
+	wordSet := words.Default()
+	for i := range b.N {
+		_ = wordSet.IsValidWord(benchmarkAnswers[i%len(benchmarkAnswers)])
+	}
+}
+
+// BenchmarkValidationPoolLobbies measures the pool's throughput with many lobbies sharing it, each waiting on its own results
+func BenchmarkValidationPoolLobbies(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		lobby := newTestLobby(DefaultLobbySettings())
+		defer close(lobby.done)
+
+		for i := 0; pb.Next(); i++ {
+			lobby.validateAnswer(benchmarkAnswers[i%len(benchmarkAnswers)], i)
+			<-lobby.validationResults
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package game
+
+import "github.com/jhshelnu/wordcraft/words"
+
+// loadChallengeVault returns the challenges from the lobby's ChallengeVault setting which can be answered, in order
+// challenges which couldn't be given out normally are skipped, e.g. because nobody could ever answer them
+// the vault is used at the start of the game, so challenges are held to the rules for easy challenges
+func (lobby *Lobby) loadChallengeVault() []string {
+	vault := make([]string, 0, len(lobby.settings.ChallengeVault))
+	for _, challenge := range lobby.settings.ChallengeVault {
+		if !lobby.wordList().IsValidChallenge(challenge, words.ChallengeEasy) {
+			lobby.logger.Warn("skipping vault challenge because it is too short or no words contain it", "challenge", challenge)
+			continue
+		}
+		vault = append(vault, challenge)
+	}
+	return vault
+}
+
+// takeVaultChallenge returns the next challenge from the vault, or "" once the vault is exhausted (or if it was empty to begin with)
+func (lobby *Lobby) takeVaultChallenge() string {
+	if lobby.vaultIndex >= len(lobby.challengeVault) {
+		return ""
+	}
+
+	challenge := lobby.challengeVault[lobby.vaultIndex]
+	lobby.vaultIndex++
+	return challenge
+}
@@ -1,28 +1,84 @@
 package icons
 
 import (
+	"archive/zip"
+	"bytes"
+	"embed"
 	"fmt"
+	"io/fs"
 	"math/rand/v2"
 	"os"
+	"path"
+	"strings"
 )
 
-const iconDirectory = "./static/icons"
+//go:embed icons/*
+var embeddedIcons embed.FS
+
+var iconFS fs.FS // where the icons are read from, either embeddedIcons or the directory (or zip archive) in WORDGAME_ICONS_PATH
 
 var iconNames = make([]string, 0, 9) // current number of available icons
 
+// Init loads the icon names from the icons embedded in the binary
+// or, if the WORDGAME_ICONS_PATH environment variable is set, from that directory (or .zip archive) instead
 func Init() error {
-	dirEntries, err := os.ReadDir(iconDirectory)
+	if iconsPath := os.Getenv("WORDGAME_ICONS_PATH"); strings.HasSuffix(iconsPath, ".zip") {
+		var err error
+		if iconFS, err = openZip(iconsPath); err != nil {
+			return fmt.Errorf("failed to read icons from %s: %w", iconsPath, err)
+		}
+	} else if iconsPath != "" {
+		iconFS = os.DirFS(iconsPath)
+	} else {
+		var err error
+		if iconFS, err = fs.Sub(embeddedIcons, "icons"); err != nil {
+			return fmt.Errorf("failed to read embedded icons: %w", err)
+		}
+	}
+
+	dirEntries, err := fs.ReadDir(iconFS, ".")
 	if err != nil {
-		return fmt.Errorf("failed to read directory %s: %w", iconDirectory, err)
+		return fmt.Errorf("failed to read icons: %w", err)
 	}
 
 	for _, file := range dirEntries {
+		if file.IsDir() || path.Ext(file.Name()) != ".png" {
+			continue
+		}
 		iconNames = append(iconNames, file.Name())
 	}
 
 	return nil
 }
 
+// openZip reads the zip archive at zipPath into memory, returning the directory in it holding the icons
+// that's the root of the archive, unless it only holds a single directory (e.g. when the icons directory itself was zipped)
+func openZip(zipPath string) (fs.FS, error) {
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(archive, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return fs.Sub(archive, entries[0].Name())
+	}
+	return archive, nil
+}
+
+// FS returns the file system the icons were loaded from, so they can be served to clients
+func FS() fs.FS {
+	return iconFS
+}
+
 func GetShuffledIconNames() []string {
 	iconNamesShuffled := make([]string, len(iconNames))
 	copy(iconNamesShuffled, iconNames)
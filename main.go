@@ -1,23 +1,38 @@
 package main
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/jhshelnu/wordcraft/game"
 	"github.com/jhshelnu/wordcraft/icons"
+	"github.com/jhshelnu/wordcraft/metrics"
+	"github.com/jhshelnu/wordcraft/storage"
 	"github.com/jhshelnu/wordcraft/words"
+	"io"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+	"unicode/utf8"
 )
 
 var isProd = os.Getenv("PROD") != ""
 
+// debug logging is never allowed in production since it would leak game state into the logs
+var isDebug = !isProd && os.Getenv("WORDGAME_DEBUG") == "true"
+
 var logger = log.New(os.Stdout, "Application: ", log.Lshortfile|log.Lmsgprefix)
 
 var upgrader = websocket.Upgrader{
@@ -25,14 +40,322 @@ var upgrader = websocket.Upgrader{
 	WriteBufferSize: 1024,
 }
 
-var lobbies = make(map[uuid.UUID]*game.Lobby)
+// adminToken must be sent as a bearer token to use the admin endpoints. if empty, the admin endpoints are disabled
+var adminToken = os.Getenv("WORDGAME_ADMIN_TOKEN")
+
+var lobbies = make(map[string]*game.Lobby)     // keyed by the canonical string form of each lobby's id
+var lobbyByCode = make(map[string]*game.Lobby) // the same lobbies, keyed by their code
+var lobbiesMutex sync.RWMutex                  // request handlers and handleEndedLobbies all access lobbies (and lobbyByCode) concurrently
 var lobbyEnded = make(chan uuid.UUID)
 
+// resultsStore is where the results of finished games are saved. if nil, results are not kept
+var resultsStore storage.Store
+
+const recentResultsLimit = 50 // how many results GET /api/results responds with
+
+const maxWordListBytes = 10 << 20 // the largest custom word list POST /api/lobby accepts
+
+const stateRequestTimeout = 2 * time.Second // how long GET /api/lobby/:lobbyId/state waits for the lobby to respond
+
+// clientOptions configures the heartbeat used to detect websocket connections which went away without closing
+var clientOptions = game.DefaultClientOptions()
+
+var totalConnectionsServed atomic.Uint64 // how many websocket connections have been upgraded since startup
+var activeConnections atomic.Int64       // how many upgraded websocket connections are still open
+
+func getLobby(lobbyId string) (*game.Lobby, bool) {
+	lobbiesMutex.RLock()
+	defer lobbiesMutex.RUnlock()
+
+	lobby, exists := lobbies[lobbyId]
+	return lobby, exists
+}
+
+func addLobby(lobby *game.Lobby) {
+	lobbiesMutex.Lock()
+	defer lobbiesMutex.Unlock()
+
+	// collisions are extremely rare, but a code must only ever lead to one lobby
+	for lobbyByCode[lobby.Code] != nil {
+		lobby.Code = game.NewLobbyCode()
+	}
+	lobbies[lobby.Id.String()] = lobby
+	lobbyByCode[lobby.Code] = lobby
+}
+
+func getLobbyByCode(code string) (*game.Lobby, bool) {
+	lobbiesMutex.RLock()
+	defer lobbiesMutex.RUnlock()
+
+	lobby, exists := lobbyByCode[strings.ToUpper(code)]
+	return lobby, exists
+}
+
+// creates a lobby from the settings in the request body, which is either JSON or a multipart form (see readMultipartLobby)
 func createLobby(c *gin.Context) {
-	lobby := game.NewLobby(lobbyEnded)
+	// the request body is optional, any settings not provided fall back to their defaults
+	settings := game.DefaultLobbySettings()
+	var wordSet *words.WordSet
+	if c.ContentType() == "multipart/form-data" {
+		var err error
+		if wordSet, err = readMultipartLobby(c, &settings); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+	} else if err := c.ShouldBindJSON(&settings); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("failed to parse lobby settings: %v", err)})
+		return
+	}
+
+	if errs := settings.Validate(); len(errs) > 0 {
+		violations := make([]string, 0, len(errs))
+		for _, err := range errs {
+			violations = append(violations, err.Error())
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("invalid lobby settings: %s", strings.Join(violations, "; "))})
+		return
+	}
+
+	// everyone playing the daily challenge needs to get the same challenges
+	if settings.DailyChallenge && wordSet != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid lobby settings: dailyChallenge cannot be combined with a custom word list"})
+		return
+	}
+
+	lobby := game.NewLobby(lobbyEnded, settings, c.Query("password"), resultsStore, wordSet)
 	go lobby.StartLobby()
-	lobbies[lobby.Id] = lobby
-	c.JSON(http.StatusCreated, gin.H{"lobbyId": lobby.Id})
+	addLobby(lobby)
+	c.JSON(http.StatusCreated, gin.H{"lobbyId": lobby.Id, "code": lobby.Code})
+}
+
+// readMultipartLobby reads a lobby creation request sent as a multipart form
+// the settings are given as JSON in the optional "settings" field, and the optional "wordlist" file is a custom word list with one word per line
+// returns the custom word list, or nil if none was uploaded
+func readMultipartLobby(c *gin.Context, settings *game.LobbySettings) (*words.WordSet, error) {
+	// leave some room for the rest of the form, but don't let an oversized upload be read in its entirety
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWordListBytes+1<<20)
+
+	if settingsJson := c.PostForm("settings"); settingsJson != "" {
+		if err := json.Unmarshal([]byte(settingsJson), settings); err != nil {
+			return nil, fmt.Errorf("failed to parse lobby settings: %v", err)
+		}
+	}
+
+	header, err := c.FormFile("wordlist")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read word list: %v", err)
+	}
+	if header.Size > maxWordListBytes {
+		return nil, fmt.Errorf("word list cannot be more than %d MB", maxWordListBytes>>20)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read word list: %v", err)
+	}
+	defer file.Close()
+
+	return words.NewWordSet(file)
+}
+
+// lists the lobbies which are currently open, oldest first. lobbies whose game is over (or which are shutting down) are left out
+func listLobbies(c *gin.Context) {
+	lobbiesMutex.RLock()
+	openLobbies := make([]*game.Lobby, 0, len(lobbies))
+	for _, lobby := range lobbies {
+		if lobby.IsActive() && lobby.Status() != game.Over {
+			openLobbies = append(openLobbies, lobby)
+		}
+	}
+	lobbiesMutex.RUnlock()
+
+	slices.SortFunc(openLobbies, func(l1, l2 *game.Lobby) int {
+		return l1.CreatedAt.Compare(l2.CreatedAt)
+	})
+
+	summaries := make([]gin.H, 0, len(openLobbies))
+	for _, lobby := range openLobbies {
+		summaries = append(summaries, gin.H{
+			"lobbyId":             lobby.Id,
+			"code":                lobby.Code,
+			"playerCount":         lobby.ClientCount(),
+			"minPlayers":          lobby.Settings().MinPlayers,
+			"maxPlayers":          lobby.Settings().MaxPlayers,
+			"status":              lobby.Status(),
+			"createdAt":           lobby.CreatedAt,
+			"isPasswordProtected": lobby.IsPasswordProtected(),
+		})
+	}
+	c.JSON(http.StatusOK, summaries)
+}
+
+// responds with the results of the most recently finished games, newest first
+func listResults(c *gin.Context) {
+	if resultsStore == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Game results are not being stored"})
+		return
+	}
+
+	results, err := resultsStore.RecentGameResults(recentResultsLimit)
+	if err != nil {
+		logger.Printf("Failed to list game results: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list game results"})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// responds with today's daily challenge, along with the best results from today's daily games (see LobbySettings.DailyChallenge)
+func getDailyChallenge(c *gin.Context) {
+	day, challenge := game.DailyChallenge()
+	c.JSON(http.StatusOK, gin.H{
+		"date":        day,
+		"challenge":   challenge,
+		"leaderboard": game.DailyLeaderboard(),
+	})
+}
+
+// responds with a snapshot of a lobby's state, for tooling which doesn't join lobbies over a websocket
+// like the lobby page, this requires the password of private lobbies
+func getLobbyState(c *gin.Context) {
+	parsedLobbyId, err := uuid.Parse(c.Param("lobbyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("failed to parse lobbyId: %v", err)})
+		return
+	}
+
+	lobby, exists := getLobby(parsedLobbyId.String())
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Lobby not found"})
+		return
+	}
+
+	if !lobby.CheckPassword(c.Query("password")) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This lobby requires a password"})
+		return
+	}
+
+	state, ok := lobby.RequestState(stateRequestTimeout)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"message": "The lobby did not respond, it may be shutting down"})
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// responds with how the answers to each challenge of a lobby's current (or last) game fared, keyed by challenge
+func getChallengeStats(c *gin.Context) {
+	parsedLobbyId, err := uuid.Parse(c.Param("lobbyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("failed to parse lobbyId: %v", err)})
+		return
+	}
+
+	lobby, exists := getLobby(parsedLobbyId.String())
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Lobby not found"})
+		return
+	}
+
+	if !lobby.CheckPassword(c.Query("password")) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "This lobby requires a password"})
+		return
+	}
+	c.JSON(http.StatusOK, lobby.ChallengeStats())
+}
+
+// responds to requests for a valid path that don't match any of the path's methods, e.g. GET /api/lobby
+func handleMethodNotAllowed(c *gin.Context) {
+	c.JSON(http.StatusMethodNotAllowed, gin.H{
+		"code":    "METHOD_NOT_ALLOWED",
+		"message": fmt.Sprintf("Method %s is not allowed for %s", c.Request.Method, c.Request.URL.Path),
+	})
+}
+
+// reports that the server is up, along with how much load it's under
+func handleHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":            "ok",
+		"connectionsServed": totalConnectionsServed.Load(),
+		"activeConnections": activeConnections.Load(),
+	})
+}
+
+// describes every type of websocket message, as a machine-readable reference of the protocol
+func listMessageTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, game.MessageTypes)
+}
+
+// rejects requests to admin endpoints which don't carry the admin token
+func requireAdmin(c *gin.Context) {
+	if adminToken == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Admin endpoints are disabled"})
+		return
+	}
+
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "A valid admin token is required"})
+		return
+	}
+
+	c.Next()
+}
+
+// lists the challenges of a given difficulty, so that the challenge list can be audited
+func listChallenges(c *gin.Context) {
+	difficulty, ok := words.ParseChallengeDifficulty(c.Query("difficulty"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("unknown difficulty '%s'", c.Query("difficulty"))})
+		return
+	}
+
+	challenges := words.GetChallengePool(difficulty)
+	lengths := make(map[int]int) // challenge length -> how many challenges have that length
+	for _, challenge := range challenges {
+		lengths[utf8.RuneCountInString(challenge)]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"difficulty": difficulty.String(),
+		"count":      len(challenges),
+		"lengths":    lengths,
+		"challenges": challenges,
+	})
+}
+
+// creates a new lobby with the same settings as an existing one, and offers it to the existing lobby's alive clients
+// the existing lobby carries on as normal, clients only move to the fork if they choose to
+func forkLobby(c *gin.Context) {
+	parsedLobbyId, err := uuid.Parse(c.Param("lobbyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("failed to parse lobbyId: %v", err)})
+		return
+	}
+	lobbyId := parsedLobbyId.String()
+
+	original, exists := getLobby(lobbyId)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Lobby not found"})
+		return
+	}
+
+	// the lobby is still listed until it has finished ending, but its clients wouldn't be around to be offered the fork
+	if !original.IsActive() {
+		c.JSON(http.StatusConflict, gin.H{"message": "Lobby is shutting down"})
+		return
+	}
+
+	fork := game.NewLobby(lobbyEnded, original.Settings(), original.Password(), resultsStore, original.WordSet())
+	go fork.StartLobby()
+	addLobby(fork)
+
+	if !original.OfferFork(fork.Id) {
+		logger.Printf("Lobby %s ended before its fork %s could be offered to its clients", lobbyId, fork.Id)
+	}
+	c.JSON(http.StatusCreated, gin.H{"lobbyId": fork.Id, "code": fork.Code})
 }
 
 func handleIndex(c *gin.Context) {
@@ -41,15 +364,16 @@ func handleIndex(c *gin.Context) {
 
 // navigates the user to the page for a specific lobby
 func openLobby(c *gin.Context) {
-	lobbyId, err := uuid.Parse(c.Param("lobbyId"))
+	parsedLobbyId, err := uuid.Parse(c.Param("lobbyId"))
 	if err != nil {
 		c.HTML(http.StatusOK, "home.gohtml", gin.H{
 			"error": "Invalid lobby Id",
 		})
 		return
 	}
+	lobbyId := parsedLobbyId.String()
 
-	_, exists := lobbies[lobbyId]
+	lobby, exists := getLobby(lobbyId)
 	if !exists {
 		c.HTML(http.StatusOK, "home.gohtml", gin.H{
 			"error": "Lobby not found",
@@ -57,23 +381,69 @@ func openLobby(c *gin.Context) {
 		return
 	}
 
-	c.HTML(http.StatusOK, "lobby.gohtml", gin.H{"lobbyId": lobbyId, "isProd": isProd})
+	renderLobby(c, lobby)
+}
+
+// navigates the user to the page for a specific lobby, found by its code instead of its id
+func openLobbyByCode(c *gin.Context) {
+	lobby, exists := getLobbyByCode(c.Param("code"))
+	if !exists {
+		c.HTML(http.StatusOK, "home.gohtml", gin.H{
+			"error": "Lobby not found",
+		})
+		return
+	}
+
+	renderLobby(c, lobby)
+}
+
+func renderLobby(c *gin.Context, lobby *game.Lobby) {
+	if !lobby.CheckPassword(c.Query("password")) {
+		c.HTML(http.StatusForbidden, "home.gohtml", gin.H{
+			"error": "This lobby requires a password. Ask for an invite link",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "lobby.gohtml", gin.H{"lobbyId": lobby.Id.String(), "isProd": isProd})
 }
 
 // once on the page for a specific lobby, the browser sends a request here to establish a WebSocket connection
 // this is what actually causes the user to "join" the lobby and be able to play
 func joinLobby(c *gin.Context) {
-	lobbyId, err := uuid.Parse(c.Param("lobbyId"))
+	parsedLobbyId, err := uuid.Parse(c.Param("lobbyId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("failed to parse lobbyId: %v", err)})
 		return
 	}
+	lobbyId := parsedLobbyId.String()
 
-	if _, exists := lobbies[lobbyId]; !exists {
+	lobby, exists := getLobby(lobbyId)
+	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"message": "Lobby not found"})
 		return
 	}
 
+	connectToLobby(c, lobby)
+}
+
+// the same as joinLobby, but the lobby is found by its code instead of its id
+func joinLobbyByCode(c *gin.Context) {
+	lobby, exists := getLobbyByCode(c.Param("code"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Lobby not found"})
+		return
+	}
+
+	connectToLobby(c, lobby)
+}
+
+func connectToLobby(c *gin.Context, lobby *game.Lobby) {
+	if !lobby.CheckPassword(c.Query("password")) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Incorrect password for this lobby"})
+		return
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade ws connection: %v\n", err)
@@ -82,8 +452,26 @@ func joinLobby(c *gin.Context) {
 		})
 		return
 	}
+	totalConnectionsServed.Add(1)
+	activeConnections.Add(1) // decremented by game.ClientDisconnected once the connection closes
 
-	err = game.JoinClientToLobby(conn, lobbies[lobbyId])
+	err = game.JoinClientToLobby(conn, lobby, c.Query("rejoinToken"), clientOptions)
+	var joinErr game.JoinError
+	if errors.As(err, &joinErr) && joinErr.Code == game.LobbyEnded {
+		// the lobby ended after we checked that it exists. the connection has already been closed, so there's nothing left to do
+		logger.Printf("Client could not join lobby %s because it ended while they were joining", lobby.Id)
+		return
+	}
+	if errors.As(err, &joinErr) && joinErr.Code == game.Kicked {
+		// the connection has already been closed, with a close frame telling the client why
+		logger.Printf("Client could not join lobby %s because they were kicked from it", lobby.Id)
+		return
+	}
+	if errors.As(err, &joinErr) && joinErr.Code == game.NotFinalist {
+		// the connection has already been closed, with a close frame telling the client why
+		logger.Printf("Client could not join lobby %s because it is a tournament final they didn't qualify for", lobby.Id)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to join lobby. The connection was not properly added to the lobby."})
 		return
@@ -93,40 +481,90 @@ func joinLobby(c *gin.Context) {
 func handleEndedLobbies() {
 	for {
 		endedLobbyId := <-lobbyEnded
-		delete(lobbies, endedLobbyId)
+		lobbiesMutex.Lock()
+		if lobby, exists := lobbies[endedLobbyId.String()]; exists {
+			delete(lobbyByCode, lobby.Code)
+		}
+		delete(lobbies, endedLobbyId.String())
+		lobbiesMutex.Unlock()
 	}
 }
 
-func main() {
-	if err := words.Init(); err != nil {
-		log.Fatal(err)
-	}
-
-	if err := icons.Init(); err != nil {
-		log.Fatal(err)
-	}
-
-	go handleEndedLobbies()
-
-	if isProd {
-		gin.SetMode(gin.ReleaseMode)
-	}
+// newServer sets up the routes of every page, API endpoint and websocket served by the application
+func newServer() *gin.Engine {
 	server := gin.New()
+	server.HandleMethodNotAllowed = true
+	server.NoMethod(handleMethodNotAllowed)
+
+	server.GET("/health", handleHealth)
+	server.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Static assets
 	server.Static("/static", "./static")
+	server.StaticFS("/icons", http.FS(icons.FS()))
 
 	// API
 	apiGroup := server.Group("/api")
 	apiGroup.POST("/lobby", createLobby)
+	apiGroup.GET("/lobby/:lobbyId/state", getLobbyState)
+	apiGroup.GET("/lobbies", listLobbies)
+	apiGroup.GET("/message-types", listMessageTypes)
+	apiGroup.GET("/daily-challenge", getDailyChallenge)
+	apiGroup.GET("/results", listResults)
+	apiGroup.GET("/challenges", requireAdmin, listChallenges)
+	apiGroup.GET("/lobbies/:lobbyId/challenge-stats", getChallengeStats)
+	apiGroup.POST("/lobbies/:lobbyId/fork", requireAdmin, forkLobby)
 
 	// HTML
 	server.LoadHTMLGlob("templates/*.gohtml")
 	server.GET("/", handleIndex)
 	server.GET("/lobby/:lobbyId", openLobby)
+	server.GET("/lobby/code/:code", openLobbyByCode)
 
 	// WebSocket
 	server.GET("/ws/:lobbyId", joinLobby)
+	server.GET("/ws/code/:code", joinLobbyByCode)
+
+	return server
+}
+
+func main() {
+	if err := words.Init(os.Getenv("WORDGAME_WORDS_SOURCE")); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := icons.Init(); err != nil {
+		log.Fatal(err)
+	}
+
+	if isProd {
+		game.Logger = game.NewLogger(true)
+	}
+
+	if isDebug {
+		logger.Printf("WORDGAME_DEBUG is set. All websocket messages will be logged.")
+		game.DebugMessages = true
+	}
+
+	game.TournamentWebhookURL = os.Getenv("WORDGAME_TOURNAMENT_WEBHOOK_URL")
+	game.DailySecret = os.Getenv("WORDGAME_DAILY_SECRET")
+
+	if resultsPath := os.Getenv("WORDGAME_RESULTS_DB"); resultsPath != "" {
+		store, err := storage.OpenSQLite(resultsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		resultsStore = store
+	}
+	game.ClientDisconnected = func() { activeConnections.Add(-1) }
+	game.FindLobby = func(lobbyId uuid.UUID) (*game.Lobby, bool) { return getLobby(lobbyId.String()) }
+
+	go handleEndedLobbies()
+
+	if isProd {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	server := newServer()
 
 	go func() {
 		err := server.Run()
@@ -135,18 +573,22 @@ func main() {
 		}
 	}()
 
-	shutdownRequested := make(chan os.Signal)
+	shutdownRequested := make(chan os.Signal, 1)
 	signal.Notify(shutdownRequested, syscall.SIGTERM, syscall.SIGINT)
 
 	<-shutdownRequested
-	if len(lobbies) == 0 {
+	lobbiesMutex.RLock()
+	openLobbies := slices.Collect(maps.Values(lobbies))
+	lobbiesMutex.RUnlock()
+
+	if len(openLobbies) == 0 {
 		logger.Printf("Received request to shutdown. No lobbies in progress. Goodbye.")
 		os.Exit(0)
 	}
 
-	logger.Printf("Received request to shutdown. Notifying %d lobbies first. Goodbye.", len(lobbies))
-	for _, lobby := range lobbies {
-		lobby.BroadcastShutdown()
+	logger.Printf("Received request to shutdown. Notifying %d lobbies first. Goodbye.", len(openLobbies))
+	for _, lobby := range openLobbies {
+		lobby.BroadcastShutdown(game.ServerRestartShutdown)
 	}
 	time.Sleep(8 * time.Second) // give the clients enough time to see the shutdown message and be redirected to the home screen
 	os.Exit(0)
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/jhshelnu/wordcraft/icons"
+	"github.com/jhshelnu/wordcraft/words"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := words.Init(""); err != nil {
+		log.Fatal(err)
+	}
+	if err := icons.Init(); err != nil {
+		log.Fatal(err)
+	}
+	go handleEndedLobbies()
+
+	os.Exit(m.Run())
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server := newServer()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/lobby", nil))
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /api/lobby responded with status %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET /api/lobby responded with invalid JSON: %v", err)
+	}
+	if body.Code != "METHOD_NOT_ALLOWED" {
+		t.Errorf("code = %q, want %q", body.Code, "METHOD_NOT_ALLOWED")
+	}
+	if body.Message == "" {
+		t.Error("message is empty")
+	}
+}
+
+func TestListLobbies(t *testing.T) {
+	server := httptest.NewServer(newServer())
+	defer server.Close()
+
+	// forget any lobbies left over from other tests, so only the ones created here are listed
+	lobbiesMutex.Lock()
+	clear(lobbies)
+	clear(lobbyByCode)
+	lobbiesMutex.Unlock()
+
+	lobbyIds := make([]string, 3)
+	for i := range lobbyIds {
+		response, err := http.Post(server.URL+"/api/lobby", "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to create a lobby: %v", err)
+		}
+		var body struct {
+			LobbyId string `json:"lobbyId"`
+		}
+		err = json.NewDecoder(response.Body).Decode(&body)
+		_ = response.Body.Close()
+		if err != nil {
+			t.Fatalf("POST /api/lobby responded with invalid JSON: %v", err)
+		}
+		lobbyIds[i] = body.LobbyId
+	}
+
+	expectListed(t, server, lobbyIds)
+
+	// a lobby ends once its last client leaves, so end the middle one by joining and leaving it
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/"+lobbyIds[1], nil)
+	if err != nil {
+		t.Fatalf("failed to join a lobby: %v", err)
+	}
+	_ = conn.Close()
+
+	expectListed(t, server, []string{lobbyIds[0], lobbyIds[2]})
+}
+
+// expectListed fails the test unless GET /api/lobbies lists exactly the lobbies with the given ids, in order, within a couple of seconds
+// lobbies start (and end) in the background, so it takes a moment for the listing to catch up
+func expectListed(t *testing.T, server *httptest.Server, want []string) {
+	t.Helper()
+
+	var listed []string
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		listed = listLobbyIds(t, server)
+		if strings.Join(listed, ",") == strings.Join(want, ",") {
+			return
+		}
+	}
+	t.Fatalf("GET /api/lobbies listed %v, want %v", listed, want)
+}
+
+// listLobbyIds returns the ids of the lobbies listed by GET /api/lobbies, in order
+func listLobbyIds(t *testing.T, server *httptest.Server) []string {
+	t.Helper()
+
+	response, err := http.Get(server.URL + "/api/lobbies")
+	if err != nil {
+		t.Fatalf("failed to list lobbies: %v", err)
+	}
+	defer response.Body.Close()
+
+	var summaries []struct {
+		LobbyId string `json:"lobbyId"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&summaries); err != nil {
+		t.Fatalf("GET /api/lobbies responded with invalid JSON: %v", err)
+	}
+	lobbyIds := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		lobbyIds = append(lobbyIds, summary.LobbyId)
+	}
+	return lobbyIds
+}
+
+func TestChallengeStatsPassword(t *testing.T) {
+	server := newServer()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/lobby?password=secret", nil))
+	var body struct {
+		LobbyId string `json:"lobbyId"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("POST /api/lobby responded with invalid JSON: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "no password", query: "", wantStatus: http.StatusForbidden},
+		{name: "wrong password", query: "?password=guess", wantStatus: http.StatusForbidden},
+		{name: "right password", query: "?password=secret", wantStatus: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			path := "/api/lobbies/" + body.LobbyId + "/challenge-stats" + test.query
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			if recorder.Code != test.wantStatus {
+				t.Errorf("GET %s responded with status %d, want %d", path, recorder.Code, test.wantStatus)
+			}
+		})
+	}
+}
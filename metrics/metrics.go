@@ -0,0 +1,43 @@
+// Package metrics exposes statistics about lobbies and games for Prometheus to scrape
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// these are all registered with the default Prometheus registry, which Handler serves
+var (
+	ActiveLobbies = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "wordgame_active_lobbies",
+		Help: "How many lobbies are running right now.",
+	})
+	GamesPlayed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wordgame_total_games_played_total",
+		Help: "How many games have been played to the end.",
+	})
+	AnswersAccepted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wordgame_answers_accepted_total",
+		Help: "How many answers have been accepted.",
+	})
+	AnswersRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wordgame_answers_rejected_total",
+		Help: "How many answers have been rejected, by why they were rejected.",
+	}, []string{"rejection_reason"})
+	ClientsConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "wordgame_clients_connected",
+		Help: "How many clients are in a lobby right now.",
+	})
+	TurnDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "wordgame_turn_duration_seconds",
+		Help:    "How long turns last, from the challenge being given until an answer is accepted or time runs out.",
+		Buckets: []float64{1, 2, 3, 5, 7.5, 10, 15, 20, 30, 45, 60},
+	})
+)
+
+// Handler serves every registered metric in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -1,6 +1,8 @@
-function toast(msg, alertClass) {
+// destination is optional. if given, clicking the toast navigates there
+function toast(msg, alertClass, destination) {
     Toastify({
         text: msg,
+        destination: destination,
         duration: 4_000, // ms
         close: true,
         gravity: "top",
@@ -15,25 +15,66 @@ const GAME_OVER       = "game_over"       // the game is over
 const RESTART_GAME    = "restart_game"    // sent from a client to initiate a game restart. sever then rebroadcasts to all clients to confirm
 const NAME_CHANGE     = "name_change"     // used by clients to indicate they want a new display name
 const SHUTDOWN        = "shutdown"         // tells the clients the server is being shutdown now
+const ADVANCE_TO_NEXT_ROUND = "advance_to_next_round" // tells the clients of a tournament lobby where the next round is being played
+const DIFFICULTY_INCREASED  = "difficulty_increased"  // the challenges have gotten harder
+const ADVANCE_TO_FINALS = "advance_to_finals" // tells the winner of a qualifying tournament lobby where the final game is being played
+const DIFFICULTY_CAPPED = "difficulty_capped" // the word list has no challenges for the current difficulty
+const DOUBLE_DOWN_ACTIVATED = "double_down_activated" // the client whose turn it is bet on their answer for double points
+const MESSAGE_REJECTED = "message_rejected" // a message we sent was malformed
+const FORK_AVAILABLE = "fork_available" // a new lobby has been forked from this one
+const CHAT_MESSAGE = "chat_message" // a chat message from one of the clients
+const INACTIVITY_WARNING = "inactivity_warning" // the lobby will be closed soon unless something happens
+const NEW_STREAK_RECORD = "new_streak_record" // a client set a new record for the most answers in a row this game
+const CURRENT_STATE = "current_state" // the current turn, which we asked for again
+const TIME_LIMIT_REDUCED = "time_limit_reduced" // turns are getting shorter (time attack lobbies only)
+const CLIENT_RECONNECTING = "client_reconnecting" // a client lost their connection mid-game, but has a while to come back
+const CLIENT_RECONNECTED = "client_reconnected" // a client who lost their connection mid-game is back
+const PROMOTE_OBSERVER = "promote_observer" // sent when we want to play in the next game after spectating. server then rebroadcasts to all clients to confirm
+const CLOCK_SYNC = "clock_sync" // the server's clock, for correcting the turn countdown
+const PERMISSION_DENIED = "permission_denied" // sent when we tried to do something only the host can
+const TRANSFER_HOST = "transfer_host" // sent when the host hands the role to someone else (or leaves). server then broadcasts the new host to all clients
+const WAITING_FOR_CHALLENGE = "waiting_for_challenge" // sent in manual challenge mode when the turn is waiting on the host to choose its challenge
+const PROVIDE_CHALLENGE = "provide_challenge" // sent by the host in manual challenge mode to choose the challenge for the turn
+const KICK_CLIENT = "kick_client" // sent by the host to remove another client from the lobby
+const CLIENT_KICKED = "client_kicked" // sent when the host removes a client from the lobby (which might be us)
+const LOBBY_FULL = "lobby_full" // the lobby already has as many clients as it allows, so we can't join it
+const NOT_ENOUGH_PLAYERS = "not_enough_players" // we tried to start the game before enough clients had joined
+const ROUND_STARTED = "round_started" // the turn has come back around to the first client, starting a new round
+const RATE_LIMITED = "rate_limited" // sent to a client whose message was dropped because they're sending messages too quickly
+const SYNC_RATE_LIMITED = "sync_rate_limited" // sent when we asked for a sync too soon after the last one
+const TEAM_LIFE_LOST = "team_life_lost" // in teams lobbies, a team's turn ran out, costing it a life (sent instead of turn_expired)
+const LIVES_UPDATED = "lives_updated" // a player lost (or won back) a life
+const ANNOUNCEMENT = "announcement" // sent by the host to broadcast some text to everyone in the lobby (along with their name)
 
 // different values for gameStatus that indicate what point we're at in the game
-const WAITING_FOR_PLAYERS = 0
-const IN_PROGRESS = 1
-const OVER = 2
+const WAITING_FOR_PLAYERS = "waiting_for_players"
+const IN_PROGRESS = "in_progress"
+const OVER = "over"
 
 let ws                    // the websocket connection
+const lobbyPassword = new URLSearchParams(location.search).get("password") // the lobby's password, if it has one
 let myClientId            // our assigned id for the lobby we're joining
+let mySeatIds = []        // in hot seat lobbies, the ids of the players sharing our device
 let gameStatus            // the status of the game
+let livesByClient = {}    // how many lives each player has left this game, indexed by their id (empty in teams lobbies)
+let minPlayers            // how many clients need to be in the lobby before the game can be started
 let myDisplayNameInput    // the <input> which holds our current displayName
 let startGameButton       // the button to start the game
 let restartGameButton     // the button to restart the game
+let joinNextGameButton    // the button spectators use to play in the next game
+let amSpectator = false   // whether we joined mid-game, and are only watching until we ask to play
+let hostId                // the id of the client who can start and restart the game
+let kickReason            // why we were removed from the lobby, if we were (see onClientKicked)
 let inviteButton          // the button that copies the lobby link to the clipboard
 let inviteButtonText      // the text of the invite button (changes after being clicked)
 let clientsTurnId         // the id of the client whose turn it is
 let challengeInputSection // the part of the page to get the user's input (only shown during their turn)
 let answerInput           // the input element which holds what the user has typed so far
+let provideChallengeSection // in manual challenge mode, the part of the page for the host to choose the next challenge
+let provideChallengeInput // the input element which holds the challenge the host is choosing
 let statusText            // large text at the top of the screen displaying the current status (current challenge, who won, etc.)
 let turnCountdownInterval // the interval where we count down how many seconds the user has left
+let serverClockOffset = 0 // how many milliseconds the server's clock is ahead of ours, see onClockSync
 let suggestionsTable      // the <table> holding suggestions
 let suggestionsBody       // the <tbody> holding the specific suggestions
 
@@ -45,13 +86,26 @@ let clientEliminated       // what plays when time runs out for a client
 document.addEventListener("DOMContentLoaded", () => {
     // establish websocket connection right away
     const protocol = isProd ? "wss" : "ws"
-    ws = new WebSocket(`${protocol}://${location.host}/ws/${lobbyId}`)
+    let query = new URLSearchParams()
+    // password protected lobbies are joined through an invite link which carries the password
+    if (lobbyPassword) {
+        query.set("password", lobbyPassword)
+    }
+    // if we were in this lobby before refreshing, ask to take our place back
+    let rejoinToken = sessionStorage.getItem(`rejoinToken:${lobbyId}`)
+    if (rejoinToken) {
+        query.set("rejoinToken", rejoinToken)
+    }
+    ws = new WebSocket(`${protocol}://${location.host}/ws/${lobbyId}?${query}`)
     startGameButton = document.getElementById("start-game-button")
     restartGameButton = document.getElementById("restart-game-button")
+    joinNextGameButton = document.getElementById("join-next-game-button")
     inviteButton = document.getElementById("invite-button")
     inviteButtonText = document.getElementById("invite-button-text")
     challengeInputSection = document.getElementById("challenge-input-section")
     answerInput = document.getElementById("answer-input")
+    provideChallengeSection = document.getElementById("provide-challenge-section")
+    provideChallengeInput = document.getElementById("provide-challenge-input")
     statusText = document.getElementById("status-text")
     suggestionsTable = document.getElementById("suggestions-table")
     suggestionsBody = document.getElementById("suggestions-body")
@@ -64,6 +118,10 @@ document.addEventListener("DOMContentLoaded", () => {
         ws.send(JSON.stringify({ Type: START_GAME }))
     })
 
+    joinNextGameButton.addEventListener("click", () => {
+        ws.send(JSON.stringify({ Type: PROMOTE_OBSERVER }))
+    })
+
     restartGameButton.addEventListener("click", () => {
         ws.send(JSON.stringify({ Type: RESTART_GAME }))
     })
@@ -73,8 +131,29 @@ document.addEventListener("DOMContentLoaded", () => {
         inviteButtonText.textContent = "Copied!"
     })
 
+    ws.onclose = ({ reason }) => {
+        // we were kicked (possibly earlier, in which case the server refused to let us back in)
+        if (reason === "kicked") {
+            leaveKickedLobby()
+        }
+        // the lobby is a tournament final, which only lets in the winners of the qualifying lobbies
+        if (reason === "not_finalist") {
+            toast("Only tournament finalists can join this lobby. Leaving lobby...", "alert-warning")
+            setTimeout(() => {
+                location.href = "/"
+            }, 4_000)
+        }
+    }
+
     ws.onmessage = ({ data }) => {
-        let message = JSON.parse(data)
+        // lobbies which batch messages send several at once, as an array
+        let messages = JSON.parse(data)
+        for (let message of Array.isArray(messages) ? messages : [messages]) {
+            handleMessage(message)
+        }
+    }
+
+    function handleMessage(message) {
         let type = message["Type"]
         let content = message["Content"]
         switch (type) {
@@ -112,11 +191,103 @@ document.addEventListener("DOMContentLoaded", () => {
                 onRestartGame()
                 break
             case SHUTDOWN:
-                onShutdown()
+                onShutdown(content)
+                break
+            case ADVANCE_TO_NEXT_ROUND:
+                onAdvanceToNextRound(content)
+                break
+            case DIFFICULTY_INCREASED:
+                onDifficultyIncreased(content)
+                break
+            case ADVANCE_TO_FINALS:
+                onAdvanceToFinals(content)
+                break
+            case DIFFICULTY_CAPPED:
+                onDifficultyCapped(content)
+                break
+            case DOUBLE_DOWN_ACTIVATED:
+                onDoubleDownActivated(content)
+                break
+            case MESSAGE_REJECTED:
+                console.warn(`Server rejected our ${content["Type"]} message: ${content["Message"]}`)
+                break
+            case FORK_AVAILABLE:
+                onForkAvailable(content)
+                break
+            case CHAT_MESSAGE:
+                onChatMessage(content)
+                break
+            case INACTIVITY_WARNING:
+                onInactivityWarning(content)
+                break
+            case NEW_STREAK_RECORD:
+                onNewStreakRecord(content)
+                break
+            case CURRENT_STATE:
+                onClientsTurn(content)
+                break
+            case TIME_LIMIT_REDUCED:
+                onTimeLimitReduced(content)
+                break
+            case CLIENT_RECONNECTING:
+                onClientReconnecting(content)
+                break
+            case CLIENT_RECONNECTED:
+                onClientReconnected(content)
+                break
+            case PROMOTE_OBSERVER:
+                onPromoteObserver(content)
+                break
+            case CLOCK_SYNC:
+                onClockSync(content)
+                break
+            case PERMISSION_DENIED:
+                onPermissionDenied()
+                break
+            case TRANSFER_HOST:
+                onTransferHost(content)
+                break
+            case WAITING_FOR_CHALLENGE:
+                onWaitingForChallenge(content)
+                break
+            case CLIENT_KICKED:
+                onClientKicked(content)
+                break
+            case LOBBY_FULL:
+                onLobbyFull(content)
+                break
+            case NOT_ENOUGH_PLAYERS:
+                onNotEnoughPlayers(content)
+                break
+            case ROUND_STARTED:
+                onRoundStarted(content)
+                break
+            case RATE_LIMITED:
+                onRateLimited(content)
+                break
+            case SYNC_RATE_LIMITED:
+                onSyncRateLimited(content)
+                break
+            case TEAM_LIFE_LOST:
+                onTeamLifeLost(content)
+                break
+            case LIVES_UPDATED:
+                onLivesUpdated(content)
+                break
+            case ANNOUNCEMENT:
+                onAnnouncement(content)
                 break
         }
     }
 
+    provideChallengeInput.addEventListener("keyup", e => {
+        let challenge = provideChallengeInput.value.toLowerCase()
+        if (e.key === "Enter" && challenge) {
+            ws.send(JSON.stringify({ Type: PROVIDE_CHALLENGE, Content: challenge }))
+            provideChallengeInput.value = ""
+        }
+    })
+
     answerInput.addEventListener("input", () => {
         let currentInput = answerInput.value.toLowerCase()
         ws.send(JSON.stringify({ Type: ANSWER_PREVIEW, Content: currentInput }))
@@ -130,7 +301,7 @@ document.addEventListener("DOMContentLoaded", () => {
     })
 })
 
-// this message is broadcast from the server to one particular client at the moment of connection
+// this message is broadcast from the server to one particular client at the moment of connection (or again after a sync_request)
 // its job is to catch the client up on details-- what their id is, the current state of the game, etc
 function onClientDetails(content) {
     myClientId = content["ClientId"] // this is our assigned clientId for the rest of the lobby
@@ -141,11 +312,23 @@ function onClientDetails(content) {
     let currentAnswerPrev = content["CurrentAnswerPrev"] // what the client whose turn it is currently has typed in
     let turnEnd = content["TurnEnd"] // milliseconds from unix epoch (UTC), or 0 if not applicable
     let winnersName = content["WinnersName"] // name of the client who won (at the moment of winning), or "" if not applicable
-
-    // render the clients
+    minPlayers = content["MinPlayers"] // how many clients need to be in the lobby before the game can be started
+    mySeatIds = content["SeatIds"] ?? [] // only sent in hot seat lobbies
+    sessionStorage.setItem(`rejoinToken:${lobbyId}`, content["RejoinToken"]) // lets us take our place back if the page is refreshed
+    hostId = content["HostId"]
+    livesByClient = content["Lives"] ?? {}
+
+    // render the clients (after a sync, replacing the cards we already have, apart from our own)
+    document.querySelectorAll(`[data-client-id]:not([data-client-id="${myClientId}"])`).forEach(card => card.remove())
     clients.forEach(client => {
-        renderNewClientCard(client["Id"], client["DisplayName"], client["IconName"], client["Alive"], false)
+        // clients who lost their connection are shown the same way as eliminated ones until they come back
+        renderNewClientCard(client["Id"], client["DisplayName"], client["IconName"], client["Alive"] && !client["Reconnecting"], false, client["IsSpectator"])
     })
+    renderHost()
+
+    if (content["Rejoined"]) {
+        toast("Welcome back! You're still in the game.", "alert-success")
+    }
 
     // then render the other buttons, etc. depending on the game state
     switch (gameStatus) {
@@ -154,7 +337,8 @@ function onClientDetails(content) {
             inviteButton.classList.remove("hidden")
             break
         case IN_PROGRESS:
-            if (currentTurnId) {
+            // when rejoining, our own card isn't rendered until the client_joined message that follows
+            if (currentTurnId && currentTurnId !== myClientId) {
                 document.querySelector(`[data-client-id="${currentTurnId}"] [data-current-guess]`).textContent = currentAnswerPrev
                 document.querySelector(`[data-client-id="${currentTurnId}"] [data-current-guess-pill]`).classList.remove("invisible")
                 clientsTurnId = currentTurnId
@@ -165,7 +349,7 @@ function onClientDetails(content) {
             }
             break
         case OVER:
-            statusText.textContent = `🎉 ${winnersName} has won! 🎉`
+            statusText.textContent = winnersName ? `🎉 ${winnersName} has won! 🎉` : "Game over!"
             statusText.classList.remove("hidden")
 
             restartGameButton.classList.remove("hidden")
@@ -179,13 +363,16 @@ function onClientJoined(content) {
     let displayName = content["DisplayName"]
     let iconName    = content["IconName"]
     let isAlive     = content["Alive"]
+    let isSpectator = content["IsSpectator"] // spectators joined mid-game, and are only watching until the next one
+    hostId = content["HostId"]
 
     if (newClientId !== myClientId) {
         // if the new client is not us, this is easy
-        renderNewClientCard(newClientId, displayName, iconName, isAlive, false)
+        renderNewClientCard(newClientId, displayName, iconName, isAlive, false, isSpectator)
     } else {
         // if this is us, we do have some setup to do like registering event handlers
-        renderNewClientCard(newClientId, displayName, iconName, isAlive, true)
+        amSpectator = isSpectator
+        renderNewClientCard(newClientId, displayName, iconName, isAlive, true, isSpectator)
         myDisplayNameInput = document.getElementById("my-display-name")
 
         // on change, broadcast new name to the other clients
@@ -217,20 +404,25 @@ function onClientJoined(content) {
         // once joined, pre-select the text for convenience
         myDisplayNameInput.select()
     }
+    renderHost()
 
     clientJoinedAudio.volume = VOLUME
     clientJoinedAudio.play()
 
-    if (document.getElementById("clients-list").children.length >= 2) {
+    if (document.querySelectorAll("[data-client-id]").length >= minPlayers) {
         startGameButton.textContent = "Start game!"
         startGameButton.removeAttribute("disabled")
         restartGameButton.removeAttribute("disabled")
     }
 }
 
-function onClientLeft(leavingClientId) {
-    document.querySelector(`#clients-list [data-client-id="${leavingClientId}"]`).remove()
-    if (document.getElementById("clients-list").children.length < 2) {
+function onClientLeft(content) {
+    let leavingClientId = content["ClientId"]
+    document.querySelector(`[data-client-id="${leavingClientId}"]`).remove()
+    if (!document.getElementById("spectators-list").children.length) {
+        document.getElementById("spectators-section").classList.add("hidden")
+    }
+    if (document.querySelectorAll("[data-client-id]").length < minPlayers) {
         startGameButton.textContent = "Waiting for players..."
         startGameButton.setAttribute("disabled", "")
         restartGameButton.setAttribute("disabled", "")
@@ -243,30 +435,43 @@ function onNameChange(content) {
     // for our own name change, the user has already updated the input,
     // so, only need to handle the case of other users changing their name
     if (renamingClientId !== myClientId) {
-        document.querySelector(`#clients-list [data-client-id="${renamingClientId}"] [data-display-name]`).textContent = newDisplayName
+        document.querySelector(`[data-client-id="${renamingClientId}"] [data-display-name]`).textContent = newDisplayName
     }
 }
 
-function renderNewClientCard(clientId, displayName, iconName, alive, isMe) {
-    let clientsList = document.getElementById("clients-list")
+function renderNewClientCard(clientId, displayName, iconName, alive, isMe, isSpectator) {
+    let clientsList = document.getElementById(isSpectator ? "spectators-list" : "clients-list")
+    if (isSpectator) {
+        document.getElementById("spectators-section").classList.remove("hidden")
+    }
     let template = document.createElement("template")
     template.innerHTML = `
         <div data-client-id="${clientId}" class="card card-compact bg-base-100 w-52 shadow-2xl ${!alive ? "opacity-40" : ""}">
             <img
                 class="mask max-w-36 mx-auto"
-                src="/static/icons/${iconName}"
+                src="/icons/${iconName}"
                 alt="${iconName}" />
             <div class="card-body items-center">
+                <span data-host-crown class="hidden" title="Host">👑</span>
                 ${isMe
                     ? `<input id="my-display-name" class="input card-title text-center w-44" value="${displayName}">`
                     : `<p data-display-name class="card-title">${displayName}</p>`
                 }
+                <p data-lives class="${livesByClient[clientId] ? "" : "hidden"}">${"❤️".repeat(livesByClient[clientId] ?? 0)}</p>
                 <div data-current-guess-pill class="rounded-full min-w-24 h-8 leading-8 bg-secondary text-center invisible">
                     <p data-current-guess class="font-bold px-3" style="color: oklch(var(--sc))"></p>
                 </div>
+                ${!isMe ? `<button data-make-host class="btn btn-outline hidden">Make host</button>` : ""}
+                ${!isMe ? `<button data-kick class="btn btn-outline btn-error hidden">Kick</button>` : ""}
             </div>
         </div>
     `
+    template.content.querySelector("[data-make-host]")?.addEventListener("click", () => {
+        ws.send(JSON.stringify({ Type: TRANSFER_HOST, Content: clientId }))
+    })
+    template.content.querySelector("[data-kick]")?.addEventListener("click", () => {
+        ws.send(JSON.stringify({ Type: KICK_CLIENT, Content: clientId }))
+    })
     clientsList.appendChild(template.content)
 }
 
@@ -280,8 +485,16 @@ function onClientsTurn(content) {
     let newClientsTurnId = content["ClientId"]
     let turnEnd = content["TurnEnd"] // milliseconds from unix epoch (UTC)
     let currentChallenge = content["Challenge"]
+    let validAnswerCount = content["ValidAnswerCount"] // only sent in educational mode
+    let usedAnswerCount = content["UsedAnswers"]
+    let challengeOrigin = content["ChallengeOrigin"] // only sent in educational mode
+    let roundNumber = content["RoundNumber"]
+    let practiceRoundsLeft = content["PracticeRoundActive"]?.["RemainingPracticeRounds"] // only sent during practice rounds
+    let teamId = content["TeamId"] // only sent in teams lobbies
+    let multiplier = content["MultiplierRoundActive"]?.["Multiplier"] // only sent during multiplier rounds
 
-    countDownTurn(currentChallenge, turnEnd)
+    provideChallengeSection.classList.add("hidden")
+    countDownTurn(currentChallenge, turnEnd, validAnswerCount, usedAnswerCount, challengeOrigin, roundNumber, practiceRoundsLeft, teamId, multiplier)
 
     if (clientsTurnId) {
         let previousTurnClient = document.querySelector(`[data-client-id="${clientsTurnId}"] [data-current-guess-pill]`)
@@ -293,7 +506,7 @@ function onClientsTurn(content) {
     document.querySelector(`[data-client-id="${newClientsTurnId}"] [data-current-guess]`).textContent = ""
     document.querySelector(`[data-client-id="${newClientsTurnId}"] [data-current-guess-pill]`).classList.remove("invisible")
 
-    if (myClientId === newClientsTurnId) {
+    if (isPlayedByMe(newClientsTurnId)) {
         // it's our turn
         answerInput.value = ""
         challengeInputSection.classList.remove("hidden")
@@ -306,9 +519,14 @@ function onClientsTurn(content) {
     clientsTurnId = newClientsTurnId
 }
 
-function countDownTurn(currentChallenge, turnEnd) {
+function countDownTurn(currentChallenge, turnEnd, validAnswerCount, usedAnswerCount, challengeOrigin, roundNumber, practiceRoundsLeft, teamId, multiplier) {
     statusText.innerHTML = `
-        <span class="mr-16">Challenge: ${currentChallenge}</span>
+        ${roundNumber ? `<span class="mr-16">Round ${roundNumber}</span>` : ""}
+        ${practiceRoundsLeft ? `<span class="mr-16">Practice (${practiceRoundsLeft} left)</span>` : ""}
+        ${teamId ? `<span class="mr-16">Team ${teamId}'s turn</span>` : ""}
+        ${multiplier ? `<span class="mr-16">${multiplier}x points!</span>` : ""}
+        <span class="mr-16">Challenge: ${currentChallenge}${validAnswerCount ? ` (${validAnswerCount} possible answers)` : ""}${challengeOrigin ? ` (from ${challengeOrigin})` : ""}</span>
+        ${usedAnswerCount ? `<span class="mr-16">Words used: ${usedAnswerCount}</span>` : ""}
         Time left: 
         <span class="countdown">
             <span id="seconds-left" style="--value: ${getSecondsUntil(turnEnd)}"></span>
@@ -327,14 +545,15 @@ function countDownTurn(currentChallenge, turnEnd) {
     }, 100)
 }
 
-// returns the seconds until a given time (provided as milliseconds since the unix epoch in UTC), or 0 if the timestamp has already passed
+// returns the seconds until a given time (provided as milliseconds since the unix epoch in UTC, by the server's clock), or 0 if the timestamp has already passed
 function getSecondsUntil(endMilli) {
-    const startMilli = new Date().getTime()
+    const startMilli = new Date().getTime() + serverClockOffset
     let secondsUntil = (endMilli - startMilli) / 1_000
     return Math.max(Math.round(secondsUntil), 0)
 }
 
-function onAnswerPreview(answerPreview) {
+function onAnswerPreview(content) {
+    let answerPreview = content["Preview"]
     let answerPreviewText = answerPreview.length <= 20 ? answerPreview : answerPreview.substring(0, 20).concat("...")
     document.querySelector(`[data-client-id="${clientsTurnId}"] [data-current-guess]`).textContent = answerPreviewText
 }
@@ -345,7 +564,7 @@ function onAnswerAccepted() {
 }
 
 function onAnswerRejected() {
-    if (clientsTurnId === myClientId) {
+    if (isPlayedByMe(clientsTurnId)) {
         shakeElement(answerInput, 20)
     }
 
@@ -354,12 +573,12 @@ function onAnswerRejected() {
 }
 
 function onTurnExpired(content) {
-    let eliminatedClientId = content["EliminatedClientId"]
+    let eliminatedClientId = content["ClientId"]
     let suggestions = content["Suggestions"]
     document.querySelector(`#clients-list [data-client-id="${eliminatedClientId}"]`).classList.add("opacity-40")
     clientEliminated.volume = VOLUME
     clientEliminated.play()
-    if (eliminatedClientId === myClientId) {
+    if (isPlayedByMe(eliminatedClientId)) {
         challengeInputSection.classList.add("hidden")
         clearSuggestions()
         suggestions.forEach(suggestion => renderSuggestion(suggestion))
@@ -377,9 +596,11 @@ function clearSuggestions() {
     suggestionsBody.innerHTML = ''
 }
 
-function onGameOver(winningClientId) {
+function onGameOver(content) {
+    let rarestWord = content["RarestWord"] // the least common answer accepted this game (undefined if there were none)
     clearInterval(turnCountdownInterval)
     gameStatus = OVER
+    provideChallengeSection.classList.add("hidden")
 
     const currentGuessText = document.querySelector(`[data-client-id="${clientsTurnId}"] [data-current-guess]`)
     if (currentGuessText) {
@@ -391,17 +612,36 @@ function onGameOver(winningClientId) {
         currentGuessPill.classList.add("invisible")
     }
 
-    let winnersName
-    if (winningClientId === myClientId) {
-        // we won!
-        winnersName = document.getElementById("my-display-name").value
-    } else {
-        winnersName = document.querySelector(`#clients-list [data-client-id="${winningClientId}"] [data-display-name]`).textContent
-    }
+    let winnersName = content["WinnerName"]
     statusText.textContent = `🎉 ${winnersName} has won! 🎉`
+    if (content["Reason"] === "solo_game_over") {
+        // nobody wins a solo game, so the only thing to show is the score
+        let soloScore = Object.values(content["Scores"] ?? {})[0] ?? 0
+        statusText.textContent = `Game over! You scored ${soloScore} points`
+    }
+    if (content["Reason"] === "max_turns") {
+        statusText.textContent = `🎉 ${winnersName} has won on points! 🎉`
+    }
+    if (content["Reason"] === "opponents_left" && content["WinnerId"] === myClientId) {
+        statusText.textContent = "🎉 You won because all opponents disconnected! 🎉"
+    }
+    if (rarestWord) {
+        toast(`Rarest word of the game: ${rarestWord}`, "alert-info")
+    }
+    let hardestChallenges = content["HardestChallenges"] // undefined if no answers were rejected
+    if (hardestChallenges) {
+        toast(`Hardest challenges of the game: ${hardestChallenges.join(", ")}`, "alert-info")
+    }
+    let myStanding = content["FinalStandings"]?.find(standing => standing["ClientId"] === myClientId)
+    if (myStanding && content["WinnerId"] !== myClientId) {
+        toast(`You survived for ${Math.round(myStanding["SurvivalDurationMs"] / 1000)} seconds`, "alert-info")
+    }
 
     challengeInputSection.classList.add("hidden")
     restartGameButton.classList.remove("hidden")
+    if (amSpectator) {
+        joinNextGameButton.classList.remove("hidden")
+    }
     inviteButtonText.textContent = "Copy invite link"
     inviteButton.classList.remove("hidden")
 }
@@ -409,19 +649,236 @@ function onGameOver(winningClientId) {
 function onRestartGame() {
     gameStatus = IN_PROGRESS
     restartGameButton.classList.add("hidden")
+    // spectators who didn't ask to play in this game carry on watching
+    joinNextGameButton.classList.add("hidden")
     document.querySelectorAll("#clients-list [data-client-id]").forEach(renderedClient => {
         renderedClient.classList.remove("opacity-40")
     })
     suggestionsTable.classList.add("hidden")
 }
 
-function onShutdown() {
-    toast("Server is being restarted now for upgrades. Leaving lobby...", "alert-warning")
+function onShutdown(content) {
+    switch (content?.["Reason"]) {
+        case "idle_timeout":
+            toast("This lobby has been closed due to inactivity. Leaving lobby...", "alert-warning")
+            break
+        case "admin_terminated":
+            toast("This lobby has been closed by an admin. Leaving lobby...", "alert-warning")
+            break
+        default:
+            toast("Server is being restarted now for upgrades. Leaving lobby...", "alert-warning")
+    }
     setTimeout(() => {
         location.href = "/"
     }, 4_000)
 }
 
+function onDifficultyIncreased(content) {
+    toast(`Challenges are now ${content["NewDifficulty"]}!`, "alert-info")
+}
+
+function onAdvanceToNextRound(content) {
+    let nextLobbyId = content["NextLobbyId"]
+    toast("The next round of the tournament is ready. Moving to the next lobby...", "alert-info")
+    setTimeout(() => {
+        location.href = `/lobby/${nextLobbyId}`
+    }, 4_000)
+}
+
+function onAdvanceToFinals(content) {
+    let finalLobbyId = content["FinalLobbyId"]
+    // the final lobby lets us in with the token we won with, see Lobby.RegisterFinalist
+    sessionStorage.setItem(`rejoinToken:${finalLobbyId}`, sessionStorage.getItem(`rejoinToken:${lobbyId}`))
+    toast("You have qualified for the finals! Moving to the final lobby...", "alert-success")
+    setTimeout(() => {
+        location.href = `/lobby/${finalLobbyId}`
+    }, 4_000)
+}
+
+function onDifficultyCapped(content) {
+    toast("There are no harder challenges available, so challenges will stay easier than usual", "alert-warning")
+}
+
+function onDoubleDownActivated(content) {
+    let clientId = content["ClientId"]
+    if (clientId === myClientId) {
+        toast("You doubled down! Get this one right for double points", "alert-info")
+    } else {
+        toast(`${getDisplayName(clientId)} doubled down!`, "alert-info")
+    }
+}
+
+function onForkAvailable(content) {
+    let newLobbyId = content["NewLobbyId"]
+    toast("A new lobby has been split off from this one. Click here to join it", "alert-info", `/lobby/${newLobbyId}${lobbyPassword ? `?password=${encodeURIComponent(lobbyPassword)}` : ""}`)
+}
+
+// returns the display name of the client, as shown on their card
+function getDisplayName(clientId) {
+    if (clientId === myClientId) {
+        return document.getElementById("my-display-name").value
+    }
+    return document.querySelector(`[data-client-id="${clientId}"] [data-display-name]`)?.textContent ?? "A player"
+}
+
+// returns if the client is played on this device, i.e. it's us or (in hot seat lobbies) one of our seats
+function isPlayedByMe(clientId) {
+    return clientId === myClientId || mySeatIds.includes(clientId)
+}
+
+function onChatMessage(content) {
+    toast(`${getDisplayName(content["From"])}: ${content["Text"]}`, "alert-info")
+}
+
+function onInactivityWarning(content) {
+    toast(`This lobby will close in ${content["SecondsRemaining"]} seconds due to inactivity`, "alert-warning")
+}
+
+function onNewStreakRecord(content) {
+    // the first couple of answers are too common to be worth announcing
+    if (content["Streak"] >= 3) {
+        toast(`${getDisplayName(content["ClientId"])} is on a streak of ${content["Streak"]}!`, "alert-info")
+    }
+}
+
+function onTimeLimitReduced(content) {
+    toast(`Turns are now ${content["NewLimitMs"] / 1_000} seconds!`, "alert-info")
+}
+
+function onClientReconnecting(content) {
+    let clientId = content["ClientId"]
+    document.querySelector(`#clients-list [data-client-id="${clientId}"]`).classList.add("opacity-40")
+    toast(`${getDisplayName(clientId)} lost their connection. Waiting for them to come back...`, "alert-warning")
+}
+
+function onClientReconnected(content) {
+    let clientId = content["ClientId"]
+    document.querySelector(`#clients-list [data-client-id="${clientId}"]`).classList.remove("opacity-40")
+}
+
+function onPromoteObserver(content) {
+    let promotedClientId = content["ClientId"]
+    document.getElementById("clients-list").appendChild(document.querySelector(`[data-client-id="${promotedClientId}"]`))
+    if (!document.getElementById("spectators-list").children.length) {
+        document.getElementById("spectators-section").classList.add("hidden")
+    }
+
+    if (promotedClientId === myClientId) {
+        amSpectator = false
+        joinNextGameButton.classList.add("hidden")
+    }
+}
+
+function onClockSync(content) {
+    // the message took some time to arrive, so this slightly underestimates how far ahead the server is, which is close enough
+    serverClockOffset = content["ServerNow"] - new Date().getTime()
+}
+
+function onPermissionDenied() {
+    toast("Only the host can do that", "alert-warning")
+}
+
+function onTransferHost(content) {
+    hostId = content["HostId"]
+    renderHost()
+    if (hostId === myClientId) {
+        toast("You are now the host", "alert-info")
+    }
+}
+
+// shows the crown on the host's card, and if we are the host, lets us hand the role to (or kick) the other clients
+function renderHost() {
+    document.querySelectorAll("[data-client-id]").forEach(renderedClient => {
+        let clientId = Number(renderedClient.dataset.clientId)
+        let canManage = hostId === myClientId && !mySeatIds.includes(clientId)
+        renderedClient.querySelector("[data-host-crown]").classList.toggle("hidden", clientId !== hostId)
+        renderedClient.querySelector("[data-make-host]")?.classList.toggle("hidden", !canManage)
+        renderedClient.querySelector("[data-kick]")?.classList.toggle("hidden", !canManage)
+    })
+}
+
+function onWaitingForChallenge(content) {
+    clearInterval(turnCountdownInterval)
+    challengeInputSection.classList.add("hidden")
+    if (content["RejectedChallenge"]) {
+        toast(`"${content["RejectedChallenge"]}" can't be used as a challenge, try another`, "alert-warning")
+    }
+
+    let playerName = getDisplayName(content["ClientId"])
+    if (hostId === myClientId) {
+        statusText.textContent = `Choose the next challenge for ${playerName}`
+        provideChallengeSection.classList.remove("hidden")
+        provideChallengeInput.focus()
+    } else {
+        statusText.textContent = `Waiting for the host to choose the next challenge for ${playerName}...`
+    }
+    statusText.classList.remove("hidden")
+}
+
+function onClientKicked(content) {
+    // if it was us, the server closes the connection next, which is handled by ws.onclose
+    if (content["ClientId"] === myClientId) {
+        kickReason = content["Reason"]
+    } else if (content["Reason"] === "rate_limited") {
+        toast(`${getDisplayName(content["ClientId"])} was removed for sending too many messages`, "alert-info")
+    } else {
+        toast(`${getDisplayName(content["ClientId"])} was removed by the host`, "alert-info")
+    }
+}
+
+function leaveKickedLobby() {
+    if (kickReason === "rate_limited") {
+        toast("You were removed from this lobby for sending too many messages. Leaving lobby...", "alert-warning")
+    } else {
+        toast("You were removed from this lobby by the host. Leaving lobby...", "alert-warning")
+    }
+    setTimeout(() => {
+        location.href = "/"
+    }, 4_000)
+}
+
+function onLobbyFull(content) {
+    toast(`This lobby is full (${content["MaxPlayers"]} players at most). Leaving lobby...`, "alert-warning")
+    setTimeout(() => {
+        location.href = "/"
+    }, 4_000)
+}
+
+function onNotEnoughPlayers(content) {
+    toast(`At least ${content["MinPlayers"]} players are needed to start (${content["Players"]} so far)`, "alert-warning")
+}
+
+function onRoundStarted(content) {
+    toast(`Round ${content["RoundNumber"]} – ${content["DifficultyLabel"]} difficulty!`, "alert-info")
+}
+
+function onRateLimited(content) {
+    toast(`You are sending messages too quickly. Try again in ${Math.ceil(content["RetryAfterMs"] / 1000)}s`, "alert-warning")
+}
+
+function onSyncRateLimited(content) {
+    toast(`The lobby was synced recently. Try again in ${Math.ceil(content["RetryAfterMs"] / 1000)}s`, "alert-warning")
+}
+
+function onTeamLifeLost(content) {
+    let livesLeft = content["LivesLeft"]
+    toast(`Team ${content["TeamId"]} ran out of time! ${livesLeft} ${livesLeft === 1 ? "life" : "lives"} left`, "alert-warning")
+}
+
+function onLivesUpdated(content) {
+    let clientId = content["ClientId"]
+    livesByClient[clientId] = content["RemainingLives"]
+    let livesElement = document.querySelector(`[data-client-id="${clientId}"] [data-lives]`)
+    if (livesElement) { // can be null if the client in question left
+        livesElement.textContent = "❤️".repeat(content["RemainingLives"])
+        livesElement.classList.remove("hidden")
+    }
+}
+
+function onAnnouncement(content) {
+    toast(`📢 ${content["FromName"]}: ${content["Text"]}`, "alert-success")
+}
+
 function shakeElement(e, amt) {
     gsap.to(e, {
         x: -amt,
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"time"
+)
+
+// migrations are run in order each time the database is opened, so each must be safe to run more than once
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS game_results (
+		lobby_id     TEXT    NOT NULL,
+		started_at   INTEGER NOT NULL,
+		ended_at     INTEGER NOT NULL,
+		winner_name  TEXT    NOT NULL,
+		player_count INTEGER NOT NULL,
+		total_rounds INTEGER NOT NULL,
+		words_used   INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS game_results_ended_at ON game_results (ended_at)`,
+}
+
+// SQLiteStore is a Store backed by a SQLite database file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens (creating it if needed) the SQLite database at path, and brings its tables up to date
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range migrations {
+		if _, err = db.Exec(migration); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+		}
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// times are stored as milliseconds from the unix epoch (UTC), like the timestamps sent to clients
+func (store *SQLiteStore) SaveGameResult(result GameResult) error {
+	_, err := store.db.Exec(
+		`INSERT INTO game_results (lobby_id, started_at, ended_at, winner_name, player_count, total_rounds, words_used)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		result.LobbyId.String(), result.StartedAt.UnixMilli(), result.EndedAt.UnixMilli(),
+		result.WinnerName, result.PlayerCount, result.TotalRounds, result.WordsUsed,
+	)
+	return err
+}
+
+func (store *SQLiteStore) RecentGameResults(limit int) ([]GameResult, error) {
+	rows, err := store.db.Query(
+		`SELECT lobby_id, started_at, ended_at, winner_name, player_count, total_rounds, words_used
+		FROM game_results ORDER BY ended_at DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]GameResult, 0, limit)
+	for rows.Next() {
+		var result GameResult
+		var lobbyId string
+		var startedAt, endedAt int64
+		err = rows.Scan(&lobbyId, &startedAt, &endedAt, &result.WinnerName, &result.PlayerCount, &result.TotalRounds, &result.WordsUsed)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = result.LobbyId.UnmarshalText([]byte(lobbyId)); err != nil {
+			return nil, err
+		}
+		result.StartedAt = time.UnixMilli(startedAt).UTC()
+		result.EndedAt = time.UnixMilli(endedAt).UTC()
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (store *SQLiteStore) Close() error {
+	return store.db.Close()
+}
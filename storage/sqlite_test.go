@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"github.com/google/uuid"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a store backed by a new database file, which is deleted along with the test's temp directory
+func openTestStore(t *testing.T, path string) *SQLiteStore {
+	t.Helper()
+
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func testResult(endedAt time.Time, winnerName string) GameResult {
+	return GameResult{
+		LobbyId:     uuid.New(),
+		StartedAt:   endedAt.Add(-5 * time.Minute),
+		EndedAt:     endedAt,
+		WinnerName:  winnerName,
+		PlayerCount: 3,
+		TotalRounds: 7,
+		WordsUsed:   19,
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	store := openTestStore(t, filepath.Join(t.TempDir(), "results.db"))
+
+	// times are stored to the millisecond, and read back in UTC
+	now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+	oldest := testResult(now.Add(-2*time.Hour), "alice")
+	newest := testResult(now, "") // nobody won, e.g. a solo game
+	middle := testResult(now.Add(-time.Hour), "bob")
+	for _, result := range []GameResult{oldest, newest, middle} {
+		if err := store.SaveGameResult(result); err != nil {
+			t.Fatalf("failed to save %+v: %v", result, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []GameResult
+	}{
+		{name: "all", limit: 10, want: []GameResult{newest, middle, oldest}},
+		{name: "limited", limit: 2, want: []GameResult{newest, middle}},
+		{name: "none", limit: 0, want: []GameResult{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := store.RecentGameResults(test.limit)
+			if err != nil {
+				t.Fatalf("failed to read results: %v", err)
+			}
+			if len(results) != len(test.want) {
+				t.Fatalf("read %d results, want %d", len(results), len(test.want))
+			}
+			for i := range results {
+				if results[i] != test.want[i] {
+					t.Errorf("result %d is %+v, want %+v", i, results[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	saved := testResult(time.UnixMilli(time.Now().UnixMilli()).UTC(), "alice")
+
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	if err = store.SaveGameResult(saved); err != nil {
+		t.Fatalf("failed to save %+v: %v", saved, err)
+	}
+	if err = store.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", path, err)
+	}
+
+	// the migrations run again on reopening, and must leave the existing results alone
+	results, err := openTestStore(t, path).RecentGameResults(10)
+	if err != nil {
+		t.Fatalf("failed to read results: %v", err)
+	}
+	if len(results) != 1 || results[0] != saved {
+		t.Errorf("read %+v after reopening, want [%+v]", results, saved)
+	}
+}
@@ -0,0 +1,25 @@
+// Package storage persists the results of finished games, so they can be reviewed after their lobby is gone
+package storage
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// GameResult summarizes one finished game
+type GameResult struct {
+	LobbyId     uuid.UUID `json:"lobbyId"`
+	StartedAt   time.Time `json:"startedAt"`
+	EndedAt     time.Time `json:"endedAt"`
+	WinnerName  string    `json:"winnerName"`  // the display name of the winner, at the moment they won, or "" if nobody won (e.g. a solo game)
+	PlayerCount int       `json:"playerCount"` // how many clients played the game (not counting spectators)
+	TotalRounds int       `json:"totalRounds"` // how many rounds the game lasted
+	WordsUsed   int       `json:"wordsUsed"`   // how many different answers were accepted
+}
+
+// Store is somewhere game results are kept
+type Store interface {
+	SaveGameResult(result GameResult) error
+	RecentGameResults(limit int) ([]GameResult, error) // the most recently ended games, newest first
+	Close() error
+}
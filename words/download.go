@@ -0,0 +1,76 @@
+package words
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const cachedWordListName = "wordgame-words.txt" // the name of the downloaded word list within the temp directory
+const cachedWordListURLSuffix = ".url"          // added to the cached word list's path for the file recording which url it was downloaded from
+const cacheMaxAge = 24 * time.Hour              // how long a downloaded word list is re-used before downloading it again
+
+var downloadClient = &http.Client{Timeout: 10 * time.Second}
+
+// downloadWordList downloads the word list at url into the temp directory and returns the path to it
+// if a previous download is still fresh (judged by its modification time) and came from the same url, it is re-used instead
+func downloadWordList(url string) (string, error) {
+	cachePath := filepath.Join(os.TempDir(), cachedWordListName)
+	if isCacheFresh(cachePath, url) {
+		return cachePath, nil
+	}
+
+	res, err := downloadClient.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download word list from %s: %w", url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download word list from %s: status %d", url, res.StatusCode)
+	}
+
+	// write to a temporary file first, so a failed download never leaves a partial word list in the cache
+	tempFile, err := os.CreateTemp(os.TempDir(), cachedWordListName+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to cache word list: %w", err)
+	}
+	defer os.Remove(tempFile.Name()) // no-op once renamed
+
+	_, err = io.Copy(tempFile, res.Body)
+	closeErr := tempFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to download word list from %s: %w", url, err)
+	}
+	if closeErr != nil {
+		return "", fmt.Errorf("failed to cache word list: %w", closeErr)
+	}
+
+	// forget where the old list came from before replacing it, so it can never be mistaken for the new url's
+	if err = os.Remove(cachePath + cachedWordListURLSuffix); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to cache word list: %w", err)
+	}
+	if err = os.Rename(tempFile.Name(), cachePath); err != nil {
+		return "", fmt.Errorf("failed to cache word list: %w", err)
+	}
+	if err = os.WriteFile(cachePath+cachedWordListURLSuffix, []byte(url), 0o644); err != nil {
+		return "", fmt.Errorf("failed to cache word list: %w", err)
+	}
+
+	return cachePath, nil
+}
+
+// isCacheFresh returns whether the word list cached at cachePath was downloaded from url less than cacheMaxAge ago
+// there's only the one cached list, so switching to a different url always downloads it again
+func isCacheFresh(cachePath string, url string) bool {
+	info, err := os.Stat(cachePath)
+	if err != nil || time.Since(info.ModTime()) >= cacheMaxAge {
+		return false
+	}
+
+	cachedURL, err := os.ReadFile(cachePath + cachedWordListURLSuffix)
+	return err == nil && string(cachedURL) == url
+}
@@ -0,0 +1,43 @@
+package words
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadWordListCache(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		_, _ = fmt.Fprintf(w, "list%s\n", r.URL.Path)
+	}))
+	defer server.Close()
+
+	download := func(url string, wantDownloads int) {
+		t.Helper()
+		path, err := downloadWordList(url)
+		if err != nil {
+			t.Fatalf("failed to download %s: %v", url, err)
+		}
+		if want := filepath.Join(os.TempDir(), "wordgame-words.txt"); path != want {
+			t.Errorf("cached at %s, want %s", path, want)
+		}
+		if downloads != wantDownloads {
+			t.Errorf("downloaded %d times, want %d", downloads, wantDownloads)
+		}
+	}
+
+	download(server.URL+"/a", 1)
+	download(server.URL+"/a", 1) // still fresh, so re-used
+	download(server.URL+"/b", 2) // a different url is never served another's list
+
+	contents, err := os.ReadFile(filepath.Join(os.TempDir(), "wordgame-words.txt"))
+	if err != nil || string(contents) != "list/b\n" {
+		t.Errorf("the cached list is %q (%v), want the one from /b", contents, err)
+	}
+}
@@ -0,0 +1,186 @@
+package words
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// buildChallengeIndex is called once the set's words and challenges have been loaded, see WordSet.challengeIndex
+func (set *WordSet) buildChallengeIndex() {
+	set.sortedWords = make([]string, 0, len(set.words))
+	for word := range set.words {
+		set.sortedWords = append(set.sortedWords, word)
+	}
+	slices.Sort(set.sortedWords)
+
+	minLength, maxLength := len(set.challenges[0]), 0
+	indexed := make(map[string]bool, len(set.challenges)+len(emojiWords))
+	for _, challenge := range set.challenges {
+		indexed[challenge] = true
+	}
+	for _, word := range emojiWords {
+		indexed[word] = true
+	}
+	for challenge := range indexed {
+		minLength = min(minLength, len(challenge))
+		maxLength = max(maxLength, len(challenge))
+	}
+
+	for i, word := range set.sortedWords {
+		for length := minLength; length <= maxLength; length++ {
+			for start := 0; start+length <= len(word); start++ {
+				challenge := word[start : start+length]
+				if !indexed[challenge] {
+					continue
+				}
+
+				// a word can contain the same challenge more than once, but should only be listed once
+				wordIndexes := set.challengeIndex[challenge]
+				if len(wordIndexes) == 0 || wordIndexes[len(wordIndexes)-1] != int32(i) {
+					set.challengeIndex[challenge] = append(wordIndexes, int32(i))
+				}
+			}
+		}
+	}
+}
+
+// GetWordsByChallenge returns every word containing challenge, in alphabetical order
+// challenges which aren't indexed (i.e. not from the challenge list or emoji words) fall back to a slower search of every word
+func (set *WordSet) GetWordsByChallenge(challenge string) []string {
+	wordIndexes, ok := set.challengeIndex[challenge]
+	if !ok {
+		var matches []string
+		for _, word := range set.sortedWords {
+			if strings.Contains(word, challenge) {
+				matches = append(matches, word)
+			}
+		}
+		return matches
+	}
+
+	matches := make([]string, 0, len(wordIndexes))
+	for _, i := range wordIndexes {
+		matches = append(matches, set.sortedWords[i])
+	}
+	return matches
+}
+
+// IsIndexedChallenge returns whether the words containing challenge are indexed, i.e. it's from the challenge list (or an emoji word)
+// and at least one word contains it. looking up the words for anything else means searching every word, see GetWordsByChallenge
+func (set *WordSet) IsIndexedChallenge(challenge string) bool {
+	_, ok := set.challengeIndex[challenge]
+	return ok
+}
+
+// SampleAnswers returns up to n valid answers for an indexed challenge (see IsIndexedChallenge), picked at random, or nil if it isn't indexed
+// the answers are picked by reservoir sampling, so only n words are copied out of the index however many contain the challenge
+func (set *WordSet) SampleAnswers(challenge string, n int) []string {
+	wordIndexes, ok := set.challengeIndex[challenge]
+	if !ok {
+		return nil
+	}
+
+	sample := make([]string, 0, n)
+	seen := 0 // how many valid answers have been considered so far
+	for _, i := range wordIndexes {
+		word := set.sortedWords[i]
+		if word == challenge { // the challenge itself is never a valid answer
+			continue
+		}
+
+		seen++
+		if len(sample) < n {
+			sample = append(sample, word)
+		} else if j := rand.IntN(seen); j < n {
+			sample[j] = word
+		}
+	}
+	return sample
+}
+
+// CountWordsByChallenge returns len(GetWordsByChallenge(challenge)), without building the list of words for indexed challenges
+func (set *WordSet) CountWordsByChallenge(challenge string) int {
+	if wordIndexes, ok := set.challengeIndex[challenge]; ok {
+		return len(wordIndexes)
+	}
+	return len(set.GetWordsByChallenge(challenge))
+}
+
+// FindLongestWordContaining returns the longest word which is a valid answer for challenge, or false if there are none
+// the challenge itself is never a valid answer. ties go to the word which comes first alphabetically
+func (set *WordSet) FindLongestWordContaining(challenge string) (string, bool) {
+	var longest string
+	for _, word := range set.GetWordsByChallenge(challenge) {
+		if word != challenge && len(word) > len(longest) {
+			longest = word
+		}
+	}
+	return longest, longest != ""
+}
+
+// SubstringIndex is the challenges which can be answered by at least a given number of a word set's words
+// along with the words containing each of them, see BuildSubstringIndex
+type SubstringIndex struct {
+	set        *WordSet
+	challenges []string // sorted from easiest to hardest, like the challenge list
+}
+
+// BuildSubstringIndex returns the challenges from the challenge list which at least minWordCount of the set's words are valid answers for
+// the words containing each challenge were indexed along with the set (see buildChallengeIndex), so this only filters the challenge list
+func (set *WordSet) BuildSubstringIndex(minWordCount int) *SubstringIndex {
+	return &SubstringIndex{
+		set: set,
+		challenges: slices.DeleteFunc(slices.Clone(challenges), func(challenge string) bool {
+			return set.CountValidAnswers(challenge) < minWordCount
+		}),
+	}
+}
+
+// Challenges returns every challenge in the index, from easiest to hardest
+// the returned slice is a copy, so callers are free to modify it
+func (index *SubstringIndex) Challenges() []string {
+	return slices.Clone(index.challenges)
+}
+
+// Words returns the valid answers for challenge, in alphabetical order, or nil if the challenge isn't in the index
+func (index *SubstringIndex) Words(challenge string) []string {
+	if !slices.Contains(index.challenges, challenge) {
+		return nil
+	}
+	return slices.DeleteFunc(index.set.GetWordsByChallenge(challenge), func(word string) bool {
+		return word == challenge
+	})
+}
+
+// BuildSubstringIndex is WordSet.BuildSubstringIndex for the default word set
+func BuildSubstringIndex(minWordCount int) *SubstringIndex {
+	return defaultSet.BuildSubstringIndex(minWordCount)
+}
+
+// GetWordsByChallenge is WordSet.GetWordsByChallenge for the default word set
+func GetWordsByChallenge(challenge string) []string {
+	return defaultSet.GetWordsByChallenge(challenge)
+}
+
+// CountWordsByChallenge is WordSet.CountWordsByChallenge for the default word set
+func CountWordsByChallenge(challenge string) int {
+	return defaultSet.CountWordsByChallenge(challenge)
+}
+
+// FindLongestWordContaining is WordSet.FindLongestWordContaining for the default word set
+func FindLongestWordContaining(challenge string) (string, bool) {
+	return defaultSet.FindLongestWordContaining(challenge)
+}
+
+// SortedByLength returns a copy of words sorted from longest to shortest, with words of the same length in alphabetical order
+func SortedByLength(words []string) []string {
+	sorted := slices.Clone(words)
+	slices.SortFunc(sorted, func(w1, w2 string) int {
+		if len(w1) != len(w2) {
+			return len(w2) - len(w1)
+		}
+		return strings.Compare(w1, w2)
+	})
+	return sorted
+}
@@ -0,0 +1,19 @@
+package words
+
+import (
+	"log"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the word list and challenges are read from the data directory, relative to the root of the repository
+	if err := os.Chdir(".."); err != nil {
+		log.Fatal(err)
+	}
+	if err := Init(""); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(m.Run())
+}
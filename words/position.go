@@ -0,0 +1,34 @@
+package words
+
+import "slices"
+
+// getChallengesAtPosition returns the challenges (sorted by difficulty, like challenges) which start at character index n of at least one word
+func (set *WordSet) getChallengesAtPosition(n int) []string {
+	set.challengesAtPositionMutex.Lock()
+	defer set.challengesAtPositionMutex.Unlock()
+
+	if pool, ok := set.challengesAtPosition[n]; ok {
+		return pool
+	}
+
+	maxChallengeLength := 0
+	for _, challenge := range set.challenges {
+		maxChallengeLength = max(maxChallengeLength, len(challenge))
+	}
+
+	found := make(map[string]bool, len(set.challenges))
+	for word := range set.words {
+		for length := 1; length <= maxChallengeLength && n+length <= len(word); length++ {
+			if _, isChallenge := suggestions[word[n:n+length]]; isChallenge {
+				found[word[n:n+length]] = true
+			}
+		}
+	}
+
+	// keep the original ordering, since that is what determines difficulty
+	pool := slices.DeleteFunc(slices.Clone(set.challenges), func(challenge string) bool {
+		return !found[challenge]
+	})
+	set.challengesAtPosition[n] = pool
+	return pool
+}
@@ -0,0 +1,53 @@
+package words
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// profanityList is the words Censor masks, one per line. it's deliberately minimal: only words which are offensive in any context
+//
+//go:embed profanity.txt
+var profanityList string
+
+var profanity = make(map[string]struct{})
+
+func init() {
+	for _, word := range strings.Fields(profanityList) {
+		profanity[word] = struct{}{}
+	}
+}
+
+// Censor returns text with every word from the profanity list replaced by asterisks, compared ignoring case
+// only whole words are masked, so e.g. "Scunthorpe" and "cockatoo" are left alone
+func Censor(text string) string {
+	var censored strings.Builder
+	censored.Grow(len(text))
+
+	for len(text) > 0 {
+		// copy everything up to the next word as it is
+		start := strings.IndexFunc(text, unicode.IsLetter)
+		if start == -1 {
+			break
+		}
+		censored.WriteString(text[:start])
+		text = text[start:]
+
+		end := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+		if end == -1 {
+			end = len(text)
+		}
+		word := text[:end]
+		if _, profane := profanity[strings.ToLower(word)]; profane {
+			censored.WriteString(strings.Repeat("*", utf8.RuneCountInString(word)))
+		} else {
+			censored.WriteString(word)
+		}
+		text = text[end:]
+	}
+
+	censored.WriteString(text)
+	return censored.String()
+}
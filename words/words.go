@@ -2,15 +2,26 @@ package words
 
 import (
 	"bufio"
+	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log"
 	"math/rand/v2"
 	"os"
 	"path"
+	"slices"
 	"strings"
 )
 
 const directory = "./data"
 
+// embeddedWords is a small list of common words, used if the configured word list can't be found
+//
+//go:embed words.txt
+var embeddedWords string
+
 //go:generate stringer -type ChallengeDifficulty -trimprefix Challenge
 type ChallengeDifficulty int
 
@@ -20,19 +31,63 @@ const (
 	ChallengeHard
 )
 
-var words = make(map[string]bool, 370_104)         // the number of words in word_list.txt
+// ChallengeConfig holds the rules for which challenges are given out at a difficulty
+type ChallengeConfig struct {
+	MinChallengeLength int // challenges shorter than this are never given out, e.g. a single letter is trivial to answer
+}
+
+// ChallengeConfigs holds the ChallengeConfig for each difficulty
+var ChallengeConfigs = map[ChallengeDifficulty]ChallengeConfig{
+	ChallengeEasy:   {MinChallengeLength: 2},
+	ChallengeMedium: {MinChallengeLength: 3},
+	ChallengeHard:   {MinChallengeLength: 3}, // challenge_list.txt has no challenges longer than 3 characters
+}
+
 var challenges = make([]string, 0, 2_256)          // the number of challenges in challenge_list.txt
 var suggestions = make(map[string][]string, 2_256) // the number of challenges in challenge_list.txt
+var emojiWords = make(map[string]string)           // emoji challenges, mapped to the English word they represent
+var emojis = make([]string, 0)                     // the keys of emojiWords, for random selection
+var origins = make(map[string]string)              // the language some challenges come from, e.g. "bio" is Greek
 
-func Init() error {
-	err := processFile("word_list.txt", func(word string) {
-		words[word] = true
-	})
+// Init loads the word list from source, along with the challenges (which are always read from the data directory)
+// source can be an http(s):// URL (see InitFromURL), a file path optionally prefixed with file://, or "" for the default word list
+func Init(source string) error {
+	switch {
+	case strings.HasPrefix(source, "http://"), strings.HasPrefix(source, "https://"):
+		return InitFromURL(source)
+	case source == "":
+		return initFromFile(path.Join(directory, "word_list.txt"))
+	default:
+		return initFromFile(strings.TrimPrefix(source, "file://"))
+	}
+}
+
+// InitFromURL is like Init, but downloads the word list from url
+// the download is cached, so restarting the server within cacheMaxAge of the last download will not download it again
+func InitFromURL(url string) error {
+	wordListPath, err := downloadWordList(url)
 	if err != nil {
 		return err
 	}
 
-	err = processFile("challenge_list.txt", func(line string) {
+	return initFromFile(wordListPath)
+}
+
+func initFromFile(wordListPath string) error {
+	words := make(map[string]struct{}, 370_104) // the number of words in word_list.txt
+	err := processFile(wordListPath, func(word string) {
+		words[word] = struct{}{}
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		for _, word := range strings.Fields(embeddedWords) {
+			words[word] = struct{}{}
+		}
+		log.Printf("WARN: %s not found, using embedded word list (%d words)", wordListPath, len(words))
+	} else if err != nil {
+		return err
+	}
+
+	err = processFile(path.Join(directory, "challenge_list.txt"), func(line string) {
 		tokens := strings.Split(line, ",")
 		challenge := tokens[0]
 		challengeSuggestions := tokens[1:]
@@ -45,15 +100,94 @@ func Init() error {
 		return err
 	}
 
+	err = processJsonFile("emoji_map.json", &emojiWords)
+	if err != nil {
+		return err
+	}
+
+	for emoji := range emojiWords {
+		emojis = append(emojis, emoji)
+	}
+
+	err = processJsonFile("etymology.json", &origins)
+	if err != nil {
+		return err
+	}
+
+	defaultSet = newWordSet(words)
+	if unanswerable := len(challenges) - len(defaultSet.challenges); unanswerable > 0 {
+		log.Printf("WARN: %d challenges have fewer than %d answers in the word list, so they won't be given out", unanswerable, minChallengeAnswers)
+	}
+
 	return nil
 }
 
+// IsValidWord is WordSet.IsValidWord for the default word set
 func IsValidWord(word string) bool {
-	return words[word]
+	return defaultSet.IsValidWord(word)
 }
 
+// IsValidChallenge is WordSet.IsValidChallenge for the default word set
+func IsValidChallenge(challenge string, difficulty ChallengeDifficulty) bool {
+	return defaultSet.IsValidChallenge(challenge, difficulty)
+}
+
+// GetChallenge is WordSet.GetChallenge for the default word set
 func GetChallenge(difficulty ChallengeDifficulty) string {
-	third := len(challenges) / 3
+	return defaultSet.GetChallenge(difficulty)
+}
+
+// GetChallengeAtPosition is WordSet.GetChallengeAtPosition for the default word set
+func GetChallengeAtPosition(n int, difficulty ChallengeDifficulty) string {
+	return defaultSet.GetChallengeAtPosition(n, difficulty)
+}
+
+// GetChallengePool is WordSet.GetChallengePool for the default word set
+func GetChallengePool(difficulty ChallengeDifficulty) []string {
+	return defaultSet.GetChallengePool(difficulty)
+}
+
+// GetSeededChallenge is WordSet.GetSeededChallenge for the default word set
+func GetSeededChallenge(seed [32]byte, n int, difficulty ChallengeDifficulty) string {
+	return defaultSet.GetSeededChallenge(seed, n, difficulty)
+}
+
+// seededChallenge returns the nth challenge of the given difficulty from pool, after shuffling it in the order determined by seed
+func seededChallenge(pool []string, seed [32]byte, n int, difficulty ChallengeDifficulty) string {
+	bracket := difficultyBracket(pool, difficulty)
+	if len(bracket) == 0 {
+		return ""
+	}
+
+	rand.New(rand.NewChaCha8(seed)).Shuffle(len(bracket), func(i, j int) {
+		bracket[i], bracket[j] = bracket[j], bracket[i]
+	})
+	return bracket[n%len(bracket)]
+}
+
+// ParseChallengeDifficulty parses the name of a difficulty (as returned by ChallengeDifficulty.String), ignoring case
+func ParseChallengeDifficulty(name string) (ChallengeDifficulty, bool) {
+	for _, difficulty := range []ChallengeDifficulty{ChallengeEasy, ChallengeMedium, ChallengeHard} {
+		if strings.EqualFold(name, difficulty.String()) {
+			return difficulty, true
+		}
+	}
+	return 0, false
+}
+
+// pickChallenge returns a random challenge of the given difficulty from pool, which must be sorted from easiest to hardest
+func pickChallenge(pool []string, difficulty ChallengeDifficulty) string {
+	bracket := difficultyBracket(pool, difficulty)
+	if len(bracket) == 0 {
+		return ""
+	}
+	return bracket[rand.IntN(len(bracket))]
+}
+
+// difficultyBracket returns the part of pool (sorted from easiest to hardest) which has the given difficulty
+// challenges shorter than the difficulty's MinChallengeLength are left out
+func difficultyBracket(pool []string, difficulty ChallengeDifficulty) []string {
+	third := len(pool) / 3
 	var low, high int // each difficulty bracket sets these, and the resulting challenge is in the range [low, high)
 	switch difficulty {
 	case ChallengeEasy:
@@ -67,20 +201,63 @@ func GetChallenge(difficulty ChallengeDifficulty) string {
 	case ChallengeHard:
 		// top third
 		low = 2 * third
-		high = len(challenges)
+		high = len(pool)
+	}
+
+	if high <= low {
+		// the pool is too small to be split by difficulty
+		low, high = 0, len(pool)
+	}
+
+	minLength := ChallengeConfigs[difficulty].MinChallengeLength
+	return slices.DeleteFunc(slices.Clone(pool[low:high]), func(challenge string) bool {
+		return len(challenge) < minLength
+	})
+}
+
+// GetEmojiChallenge returns a random emoji. Answers for it must contain the word the emoji represents (see GetEmojiWord)
+func GetEmojiChallenge() string {
+	return emojis[rand.IntN(len(emojis))]
+}
+
+// GetEmojiWord returns the English word represented by the emoji, or false if the emoji is not a known challenge
+func GetEmojiWord(emoji string) (string, bool) {
+	word, ok := emojiWords[emoji]
+	return word, ok
+}
+
+// GetChallengeOrigin returns the language the challenge comes from, e.g. "Latin", or false if its origin isn't known
+func GetChallengeOrigin(challenge string) (string, bool) {
+	origin, ok := origins[challenge]
+	return origin, ok
+}
+
+// WordFrequency measures how common a word is as an answer, as the fraction of challenges it answers (i.e. contains)
+// words made of unusual letter combinations answer few challenges, so they come up rarely in games and have a low frequency
+func WordFrequency(word string) float64 {
+	if len(challenges) == 0 {
+		return 0
 	}
 
-	return challenges[rand.IntN(high-low)+low]
+	answered := make(map[string]struct{})
+	for start := range len(word) {
+		for end := start + 1; end <= len(word); end++ {
+			if _, isChallenge := suggestions[word[start:end]]; isChallenge {
+				answered[word[start:end]] = struct{}{}
+			}
+		}
+	}
+	return float64(len(answered)) / float64(len(challenges))
 }
 
 func GetChallengeSuggestions(challenge string) []string {
 	return suggestions[challenge]
 }
 
-func processFile(fileName string, lineFn func(string)) error {
-	file, err := os.Open(path.Join(directory, fileName))
+func processFile(filePath string, lineFn func(string)) error {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to process file %s: %w", fileName, err)
+		return fmt.Errorf("failed to process file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
@@ -91,3 +268,16 @@ func processFile(fileName string, lineFn func(string)) error {
 
 	return nil
 }
+
+func processJsonFile(fileName string, v any) error {
+	data, err := os.ReadFile(path.Join(directory, fileName))
+	if err != nil {
+		return fmt.Errorf("failed to process file %s: %w", fileName, err)
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse file %s: %w", fileName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package words
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// benchmarkWordListSize is how many words BenchmarkIsValidWord looks answers up among
+const benchmarkWordListSize = 100_000
+
+func TestEmbeddedWordList(t *testing.T) {
+	set, err := NewWordSet(strings.NewReader(embeddedWords))
+	if err != nil {
+		t.Fatalf("the embedded word list can't be used as a word set: %v", err)
+	}
+
+	easy := 0
+	for _, challenge := range set.GetChallengePool(ChallengeEasy) {
+		if set.IsValidChallenge(challenge, ChallengeEasy) {
+			easy++
+		}
+	}
+	if easy < 100 {
+		t.Errorf("the embedded word list gives %d valid easy challenges, want at least 100", easy)
+	}
+}
+
+func BenchmarkIsValidWord(b *testing.B) {
+	// every other word of the default list, so that half the lookups are of words which aren't in the set
+	sortedWords := Default().sortedWords
+	words := make(map[string]struct{}, benchmarkWordListSize)
+	lookups := make([]string, 0, 2*benchmarkWordListSize)
+	for i := 0; i+1 < len(sortedWords) && len(words) < benchmarkWordListSize; i += 2 {
+		words[sortedWords[i]] = struct{}{}
+		lookups = append(lookups, sortedWords[i], sortedWords[i+1])
+	}
+	set := newWordSet(words)
+
+	b.ResetTimer()
+	for i := range b.N {
+		_ = set.IsValidWord(lookups[i%len(lookups)])
+	}
+}
+
+func BenchmarkGetChallenge(b *testing.B) {
+	for _, difficulty := range []ChallengeDifficulty{ChallengeEasy, ChallengeMedium, ChallengeHard} {
+		b.Run(difficulty.String(), func(b *testing.B) {
+			for range b.N {
+				_ = GetChallenge(difficulty)
+			}
+		})
+	}
+}
+
+// newSmallWordSet builds a word set from the first few hundred words of the embedded list, plus two words containing rareChallenge
+// so that rareChallenge has too few answers to be given out
+func newSmallWordSet(t *testing.T) (set *WordSet, rareChallenge string) {
+	t.Helper()
+
+	list := strings.Fields(embeddedWords)[:300]
+	rareChallenge = challenges[len(challenges)-1]
+	for _, word := range GetWordsByChallenge(rareChallenge)[:minChallengeAnswers-1] {
+		if !slices.Contains(list, word) {
+			list = append(list, word)
+		}
+	}
+
+	set, err := NewWordSet(strings.NewReader(strings.Join(list, "\n")))
+	if err != nil {
+		t.Fatalf("failed to build a word set: %v", err)
+	}
+	if answers := set.CountValidAnswers(rareChallenge); answers >= minChallengeAnswers {
+		t.Fatalf("%q has %d answers in the word set, want fewer than %d", rareChallenge, answers, minChallengeAnswers)
+	}
+	return set, rareChallenge
+}
+
+func TestChallengesAreSolvable(t *testing.T) {
+	set, rareChallenge := newSmallWordSet(t)
+
+	for _, difficulty := range []ChallengeDifficulty{ChallengeEasy, ChallengeMedium, ChallengeHard} {
+		t.Run(difficulty.String(), func(t *testing.T) {
+			pool := set.GetChallengePool(difficulty)
+			if len(pool) == 0 {
+				t.Fatal("no challenges are given out")
+			}
+			for _, challenge := range pool {
+				if answers := set.CountValidAnswers(challenge); answers < minChallengeAnswers {
+					t.Errorf("%q is in the pool with %d answers, want at least %d", challenge, answers, minChallengeAnswers)
+				}
+			}
+			if slices.Contains(pool, rareChallenge) {
+				t.Errorf("%q is in the pool", rareChallenge)
+			}
+
+			for range 100 {
+				challenge := set.GetChallenge(difficulty)
+				if answers := set.CountValidAnswers(challenge); answers < minChallengeAnswers {
+					t.Fatalf("GetChallenge returned %q, which has %d answers, want at least %d", challenge, answers, minChallengeAnswers)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildSubstringIndex(t *testing.T) {
+	set, rareChallenge := newSmallWordSet(t)
+
+	for _, minWordCount := range []int{1, minChallengeAnswers, 10} {
+		index := set.BuildSubstringIndex(minWordCount)
+		for _, challenge := range index.Challenges() {
+			answers := index.Words(challenge)
+			if len(answers) < minWordCount {
+				t.Errorf("%q is indexed with %d words, want at least %d", challenge, len(answers), minWordCount)
+			}
+			for _, word := range answers {
+				if !strings.Contains(word, challenge) || word == challenge || !set.IsValidWord(word) {
+					t.Errorf("%q is indexed as an answer for %q", word, challenge)
+				}
+			}
+		}
+
+		rareIndexed := slices.Contains(index.Challenges(), rareChallenge)
+		if want := minWordCount < minChallengeAnswers; rareIndexed != want {
+			t.Errorf("with a minimum of %d words, %q is indexed: %t, want %t", minWordCount, rareChallenge, rareIndexed, want)
+		}
+		if !rareIndexed && index.Words(rareChallenge) != nil {
+			t.Errorf("with a minimum of %d words, Words(%q) returned words for a challenge which isn't indexed", minWordCount, rareChallenge)
+		}
+	}
+}
+
+func TestSampleAnswers(t *testing.T) {
+	set := Default()
+	challenge := "ing"
+
+	seen := make(map[string]bool)
+	for range 100 {
+		sample := set.SampleAnswers(challenge, 3)
+		if len(sample) != 3 {
+			t.Fatalf("sampled %d answers for %q, want 3", len(sample), challenge)
+		}
+		for i, word := range sample {
+			if !strings.Contains(word, challenge) || word == challenge || !set.IsValidWord(word) {
+				t.Errorf("sampled %q, which isn't a valid answer for %q", word, challenge)
+			}
+			if slices.Contains(sample[:i], word) {
+				t.Errorf("sampled %q more than once in %v", word, sample)
+			}
+			seen[word] = true
+		}
+	}
+	if len(seen) <= 3 {
+		t.Errorf("100 samples only ever picked %v, want them to vary", seen)
+	}
+
+	if sample := set.SampleAnswers("zzzzq", 3); sample != nil {
+		t.Errorf("sampled %v for a challenge which isn't indexed, want nil", sample)
+	}
+}
+
+func TestCensor(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "good luck everyone!", want: "good luck everyone!"},
+		{text: "what the fuck", want: "what the ****"},
+		{text: "SHIT, that was close", want: "****, that was close"},
+		{text: "no bullshit: bitch-slapped", want: "no ********: *****-slapped"},
+		{text: "Scunthorpe has a cockatoo", want: "Scunthorpe has a cockatoo"},
+		{text: "", want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := Censor(test.text); got != test.want {
+				t.Errorf("Censor(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWordFrequency(t *testing.T) {
+	// "ing" answers the "in", "ng" and "ing" challenges, and nothing else
+	if got, want := WordFrequency("ing"), 3/float64(len(challenges)); got != want {
+		t.Errorf("WordFrequency(\"ing\") = %v, want %v", got, want)
+	}
+	if got := WordFrequency("qqqq"); got != 0 {
+		t.Errorf("WordFrequency(\"qqqq\") = %v, want 0 as it answers no challenges", got)
+	}
+	if WordFrequency("singing") <= WordFrequency("ing") {
+		t.Errorf("singing answers no more challenges than ing, want it to answer every challenge ing does and more")
+	}
+}
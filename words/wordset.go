@@ -0,0 +1,137 @@
+package words
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const MinWordSetSize = 100 // the fewest words a custom word list can have, see NewWordSet
+
+const minChallengeAnswers = 3 // challenges with fewer valid answers than this in a word set are never given out from it, since they're all but impossible
+
+// WordSet is a list of words which answers are checked against, along with the challenges which can be answered from it
+// the default word set is loaded by Init. lobbies can be given their own with NewWordSet, otherwise they use the default
+type WordSet struct {
+	// a hash set, so checking an answer takes the same time however long the word list is. the price is memory:
+	// the default list of ~370k words takes ~16MB as a map, against ~9MB as a sorted slice (which would need a binary search per lookup)
+	words      map[string]struct{}
+	challenges []string // the challenges with at least minChallengeAnswers valid answers, sorted from easiest to hardest
+
+	// the challenge index maps each challenge (and each emoji word) to the words containing it, see buildChallengeIndex
+	// words are stored as int32 indexes into sortedWords rather than as strings, which keeps the ~5 million entries at ~20MB
+	sortedWords    []string
+	challengeIndex map[string][]int32
+
+	challengesAtPosition      map[int][]string // challenges which start at a given character index of some word, computed as needed
+	challengesAtPositionMutex sync.Mutex       // lobbies run concurrently, so access to challengesAtPosition must be synchronized
+}
+
+var defaultSet = &WordSet{} // the word set loaded by Init
+
+// Default returns the word set loaded by Init, which lobbies without a custom word list use
+func Default() *WordSet {
+	return defaultSet
+}
+
+// newWordSet builds the word set for the given words, which gives out challenges from the challenge list
+// the challenge index is built once here, and then used to leave out the challenges which have too few answers in words
+func newWordSet(words map[string]struct{}) *WordSet {
+	set := &WordSet{
+		words:                words,
+		challenges:           challenges,
+		challengeIndex:       make(map[string][]int32, len(challenges)),
+		challengesAtPosition: make(map[int][]string),
+	}
+	set.buildChallengeIndex()
+	set.challenges = set.BuildSubstringIndex(minChallengeAnswers).challenges
+	return set
+}
+
+// NewWordSet reads a custom word list from r, with one word per line. words are trimmed and lowercased, and blank lines are skipped
+// the challenges it gives out are the ones from the challenge list which at least minChallengeAnswers of its words contain
+// Init must already have been called, since the challenge list is loaded along with the default word set
+func NewWordSet(r io.Reader) (*WordSet, error) {
+	words := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			words[word] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word list: %w", err)
+	}
+
+	if len(words) < MinWordSetSize {
+		return nil, fmt.Errorf("word list must contain at least %d words, got %d", MinWordSetSize, len(words))
+	}
+
+	set := newWordSet(words)
+	if len(set.challenges) == 0 {
+		return nil, errors.New("word list does not contain any words for the challenges to be taken from")
+	}
+	return set, nil
+}
+
+// Size returns how many words are in the set
+func (set *WordSet) Size() int {
+	return len(set.words)
+}
+
+func (set *WordSet) IsValidWord(word string) bool {
+	_, ok := set.words[word]
+	return ok
+}
+
+// IsValidChallenge returns whether challenge could be given out at the given difficulty
+// i.e. it's long enough for the difficulty (see ChallengeConfig) and it can be answered
+func (set *WordSet) IsValidChallenge(challenge string, difficulty ChallengeDifficulty) bool {
+	return len(challenge) >= ChallengeConfigs[difficulty].MinChallengeLength && set.CountValidAnswers(challenge) > 0
+}
+
+// CountValidAnswers returns how many words would be accepted as answers for challenge
+// this is every word containing it, except the challenge itself (which is never accepted)
+func (set *WordSet) CountValidAnswers(challenge string) int {
+	count := set.CountWordsByChallenge(challenge)
+	if set.IsValidWord(challenge) {
+		count--
+	}
+	return count
+}
+
+func (set *WordSet) GetChallenge(difficulty ChallengeDifficulty) string {
+	return pickChallenge(set.challenges, difficulty)
+}
+
+// GetChallengeAtPosition is like GetChallenge, but only returns challenges which start at character index n of at least one word
+func (set *WordSet) GetChallengeAtPosition(n int, difficulty ChallengeDifficulty) string {
+	return pickChallenge(set.getChallengesAtPosition(n), difficulty)
+}
+
+// GetChallengePool returns every challenge GetChallenge can return for the given difficulty, from easiest to hardest
+// the returned slice is a copy, so callers are free to modify it
+func (set *WordSet) GetChallengePool(difficulty ChallengeDifficulty) []string {
+	return difficultyBracket(set.challenges, difficulty)
+}
+
+// GetSeededChallenge returns the nth challenge of the given difficulty, from a shuffle of the pool determined by seed
+// the same seed always gives the same sequence of challenges (as long as the word list doesn't change), wrapping around once it runs out
+func (set *WordSet) GetSeededChallenge(seed [32]byte, n int, difficulty ChallengeDifficulty) string {
+	return seededChallenge(set.challenges, seed, n, difficulty)
+}
+
+// GetChallengeSuggestions returns the common answers for challenge, leaving out any which aren't in the set
+func (set *WordSet) GetChallengeSuggestions(challenge string) []string {
+	if set == defaultSet {
+		return suggestions[challenge]
+	}
+	return slices.DeleteFunc(slices.Clone(suggestions[challenge]), func(suggestion string) bool {
+		_, ok := set.words[suggestion]
+		return !ok
+	})
+}